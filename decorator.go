@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"gorm.io/gorm/schema"
+)
+
+// Decorator wraps repository with additional behaviour, e.g. logging,
+// metrics or caching. Decorators usually embed Repository and override
+// only methods they need:
+//
+//	type cachingRepo[T schema.Tabler] struct {
+//		repository.Repository[T]
+//	}
+//
+//	func (r cachingRepo[T]) Get(filter interface{}, options *repository.GetOptions) (*T, error) {
+//		...
+//		return r.Repository.Get(filter, options)
+//	}
+type Decorator[T schema.Tabler] func(next Repository[T]) Repository[T]
+
+// Chain wraps repository with decorators. The first decorator is the
+// outermost one, it is called first and returns last.
+func Chain[T schema.Tabler](repo Repository[T], decorators ...Decorator[T]) Repository[T] {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		repo = decorators[i](repo)
+	}
+	return repo
+}
+
+type Operation string
+
+const (
+	OperationList   Operation = "List"
+	OperationGet    Operation = "Get"
+	OperationExists Operation = "Exists"
+	OperationCount  Operation = "Count"
+	OperationSave   Operation = "Save"
+	OperationUpdate Operation = "Update"
+	OperationDelete Operation = "Delete"
+)
+
+// Interceptor is called around every repository operation. It must call
+// next to execute the operation and should return its error, unless it
+// handles it.
+type Interceptor func(operation Operation, next func() error) error
+
+// Intercept creates decorator which calls interceptor around every
+// repository operation
+func Intercept[T schema.Tabler](interceptor Interceptor) Decorator[T] {
+	return func(next Repository[T]) Repository[T] {
+		return interceptedRepo[T]{next: next, interceptor: interceptor}
+	}
+}
+
+type interceptedRepo[T schema.Tabler] struct {
+	next        Repository[T]
+	interceptor Interceptor
+}
+
+func (r interceptedRepo[T]) List(filter interface{}, options *ListOptions) (models *[]T, err error) {
+	err = r.interceptor(OperationList, func() error {
+		models, err = r.next.List(filter, options)
+		return err
+	})
+	return models, err
+}
+
+func (r interceptedRepo[T]) Get(filter interface{}, options *GetOptions) (model *T, err error) {
+	err = r.interceptor(OperationGet, func() error {
+		model, err = r.next.Get(filter, options)
+		return err
+	})
+	return model, err
+}
+
+func (r interceptedRepo[T]) Exists(filter interface{}) (exists bool, err error) {
+	err = r.interceptor(OperationExists, func() error {
+		exists, err = r.next.Exists(filter)
+		return err
+	})
+	return exists, err
+}
+
+func (r interceptedRepo[T]) Count(filter interface{}) (count int64, err error) {
+	err = r.interceptor(OperationCount, func() error {
+		count, err = r.next.Count(filter)
+		return err
+	})
+	return count, err
+}
+
+func (r interceptedRepo[T]) Save(model *T) (saved *T, err error) {
+	err = r.interceptor(OperationSave, func() error {
+		saved, err = r.next.Save(model)
+		return err
+	})
+	return saved, err
+}
+
+func (r interceptedRepo[T]) SaveReturning(model *T, options *ReturningOptions) (saved *T, err error) {
+	err = r.interceptor(OperationSave, func() error {
+		saved, err = r.next.SaveReturning(model, options)
+		return err
+	})
+	return saved, err
+}
+
+func (r interceptedRepo[T]) Update(filter interface{}, values map[string]any) (updated int64, err error) {
+	err = r.interceptor(OperationUpdate, func() error {
+		updated, err = r.next.Update(filter, values)
+		return err
+	})
+	return updated, err
+}
+
+func (r interceptedRepo[T]) UpdateReturning(filter interface{}, values map[string]any, options *ReturningOptions) (models *[]T, err error) {
+	err = r.interceptor(OperationUpdate, func() error {
+		models, err = r.next.UpdateReturning(filter, values, options)
+		return err
+	})
+	return models, err
+}
+
+func (r interceptedRepo[T]) UpdatePatch(filter interface{}, patch interface{}) (updated int64, err error) {
+	err = r.interceptor(OperationUpdate, func() error {
+		updated, err = r.next.UpdatePatch(filter, patch)
+		return err
+	})
+	return updated, err
+}
+
+func (r interceptedRepo[T]) UpdateFields(filter interface{}, model *T, fields []string) (updated int64, err error) {
+	err = r.interceptor(OperationUpdate, func() error {
+		updated, err = r.next.UpdateFields(filter, model, fields)
+		return err
+	})
+	return updated, err
+}
+
+func (r interceptedRepo[T]) UpdateAll(values map[string]any) (updated int64, err error) {
+	err = r.interceptor(OperationUpdate, func() error {
+		updated, err = r.next.UpdateAll(values)
+		return err
+	})
+	return updated, err
+}
+
+func (r interceptedRepo[T]) Delete(filter interface{}) (deleted int64, err error) {
+	err = r.interceptor(OperationDelete, func() error {
+		deleted, err = r.next.Delete(filter)
+		return err
+	})
+	return deleted, err
+}
+
+func (r interceptedRepo[T]) DeleteReturning(filter interface{}, options *ReturningOptions) (models *[]T, err error) {
+	err = r.interceptor(OperationDelete, func() error {
+		models, err = r.next.DeleteReturning(filter, options)
+		return err
+	})
+	return models, err
+}
+
+func (r interceptedRepo[T]) DeleteAll() (deleted int64, err error) {
+	err = r.interceptor(OperationDelete, func() error {
+		deleted, err = r.next.DeleteAll()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingRepo struct {
+	Repository[MyModel]
+	counts *int
+}
+
+func (r countingRepo) Count(filter interface{}) (int64, error) {
+	*r.counts++
+	return r.Repository.Count(filter)
+}
+
+func TestDecorators(t *testing.T) {
+	recorder := func(calls *[]string, name string) Decorator[MyModel] {
+		return Intercept[MyModel](func(operation Operation, next func() error) error {
+			*calls = append(*calls, fmt.Sprintf("%s %s start", name, operation))
+			err := next()
+			*calls = append(*calls, fmt.Sprintf("%s %s end", name, operation))
+			return err
+		})
+	}
+
+	t.Run("Chain decorators", func(t *testing.T) {
+		base, _ := newMemoryRepo(t)
+
+		calls := []string{}
+		counts := 0
+		repo := Chain[MyModel](
+			base,
+			recorder(&calls, "outer"),
+			func(next Repository[MyModel]) Repository[MyModel] {
+				return countingRepo{Repository: next, counts: &counts}
+			},
+			recorder(&calls, "inner"),
+		)
+
+		count, err := repo.Count(MyModelFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+
+		result, err := repo.List(MyModelFilter{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(*result))
+
+		assert.Equal(t, 1, counts)
+		assert.Equal(t, []string{
+			"outer Count start",
+			"inner Count start",
+			"inner Count end",
+			"outer Count end",
+			"outer List start",
+			"inner List start",
+			"inner List end",
+			"outer List end",
+		}, calls)
+	})
+
+	t.Run("Interceptor handles errors", func(t *testing.T) {
+		base, _ := newMemoryRepo(t)
+
+		failing := errors.New("unavailable")
+		repo := Chain(
+			Repository[MyModel](base),
+			Intercept[MyModel](func(operation Operation, next func() error) error {
+				if operation == OperationDelete {
+					return failing
+				}
+				return next()
+			}),
+		)
+
+		_, err := repo.Delete(MyModelFilter{})
+		assert.ErrorIs(t, err, failing)
+
+		count, err := repo.Count(MyModelFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("Decorate RepoBase", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		base := RepoBase[MyModel]{}
+		base.Init(db, nil)
+
+		calls := []string{}
+		repo := Chain[MyModel](&base, recorder(&calls, "log"))
+
+		sql := "SELECT count(*) FROM my_models"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql)))
+
+		_, err := repo.Count(MyModelFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"log Count start", "log Count end"}, calls)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// UpdateExpression is update value computed by the database, e.g. from
+// the current column value, which makes the update atomic:
+//
+//	repo.Update(filter, map[string]any{
+//		"cnt":        repository.Increment(1),
+//		"updated_at": repository.SetNow(),
+//	})
+type UpdateExpression interface {
+	// expression validates column field and returns SQL expression of value
+	expression(field *schema.Field) (clause.Expr, error)
+	// evaluate returns value computed from current value of column field
+	evaluate(field *schema.Field, current interface{}) (interface{}, error)
+}
+
+type incrementExpression struct {
+	value    interface{}
+	operator string
+}
+
+// Increment adds value to numeric column
+func Increment(value interface{}) UpdateExpression {
+	return incrementExpression{value: value, operator: "+"}
+}
+
+// Decrement subtracts value from numeric column
+func Decrement(value interface{}) UpdateExpression {
+	return incrementExpression{value: value, operator: "-"}
+}
+
+func (e incrementExpression) validate(field *schema.Field) error {
+	value := reflect.ValueOf(e.value)
+	switch field.DataType {
+	case schema.Int, schema.Uint:
+		if !value.CanInt() && !value.CanUint() {
+			return fmt.Errorf("invalid increment of integer column %s: %T", field.DBName, e.value)
+		}
+	case schema.Float:
+		if !value.CanInt() && !value.CanUint() && !value.CanFloat() {
+			return fmt.Errorf("invalid increment of float column %s: %T", field.DBName, e.value)
+		}
+	default:
+		return fmt.Errorf("column is not numeric: %s", field.DBName)
+	}
+	return nil
+}
+
+func (e incrementExpression) expression(field *schema.Field) (clause.Expr, error) {
+	if err := e.validate(field); err != nil {
+		return clause.Expr{}, err
+	}
+	return clause.Expr{
+		SQL:  fmt.Sprintf("? %s ?", e.operator),
+		Vars: []interface{}{clause.Column{Name: field.DBName}, e.value},
+	}, nil
+}
+
+func (e incrementExpression) evaluate(field *schema.Field, current interface{}) (interface{}, error) {
+	if err := e.validate(field); err != nil {
+		return nil, err
+	}
+	currentValue := reflect.Indirect(reflect.ValueOf(current))
+	if !currentValue.IsValid() {
+		// NULL stays NULL
+		return nil, nil
+	}
+
+	value := reflect.ValueOf(e.value)
+	sign := 1
+	if e.operator == "-" {
+		sign = -1
+	}
+	switch {
+	case currentValue.CanFloat():
+		return currentValue.Float() + float64(sign)*value.Convert(reflect.TypeOf(float64(0))).Float(), nil
+	case currentValue.CanInt():
+		return currentValue.Int() + int64(sign)*value.Convert(reflect.TypeOf(int64(0))).Int(), nil
+	case currentValue.CanUint():
+		return currentValue.Uint() + uint64(sign)*value.Convert(reflect.TypeOf(uint64(0))).Uint(), nil
+	}
+	return nil, fmt.Errorf("column is not numeric: %s", field.DBName)
+}
+
+type sqlExpression struct {
+	sql  string
+	vars []interface{}
+}
+
+// SetExpr sets column to SQL expression, it can't be evaluated by memory
+// repository
+func SetExpr(sql string, vars ...interface{}) UpdateExpression {
+	return sqlExpression{sql: sql, vars: vars}
+}
+
+func (e sqlExpression) expression(field *schema.Field) (clause.Expr, error) {
+	return clause.Expr{SQL: e.sql, Vars: e.vars}, nil
+}
+
+func (e sqlExpression) evaluate(field *schema.Field, current interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("sql expression can't be evaluated in memory: %s", field.DBName)
+}
+
+type coalesceExpression struct {
+	value interface{}
+}
+
+// Coalesce sets column to value if it is NULL
+func Coalesce(value interface{}) UpdateExpression {
+	return coalesceExpression{value: value}
+}
+
+func (e coalesceExpression) validate(field *schema.Field) error {
+	valueType := indirectType(reflect.TypeOf(e.value))
+	if e.value == nil || !valueType.AssignableTo(indirectType(field.FieldType)) {
+		return fmt.Errorf("invalid type for column %s: %T, expected %v", field.DBName, e.value, indirectType(field.FieldType))
+	}
+	return nil
+}
+
+func (e coalesceExpression) expression(field *schema.Field) (clause.Expr, error) {
+	if err := e.validate(field); err != nil {
+		return clause.Expr{}, err
+	}
+	return clause.Expr{
+		SQL:  "COALESCE(?, ?)",
+		Vars: []interface{}{clause.Column{Name: field.DBName}, e.value},
+	}, nil
+}
+
+func (e coalesceExpression) evaluate(field *schema.Field, current interface{}) (interface{}, error) {
+	if err := e.validate(field); err != nil {
+		return nil, err
+	}
+	if reflect.Indirect(reflect.ValueOf(current)).IsValid() {
+		return current, nil
+	}
+	return e.value, nil
+}
+
+type nowExpression struct{}
+
+// SetNow sets time column to the current time of the database
+func SetNow() UpdateExpression {
+	return nowExpression{}
+}
+
+func (e nowExpression) validate(field *schema.Field) error {
+	if field.DataType != schema.Time {
+		return fmt.Errorf("column is not a time: %s", field.DBName)
+	}
+	return nil
+}
+
+func (e nowExpression) expression(field *schema.Field) (clause.Expr, error) {
+	if err := e.validate(field); err != nil {
+		return clause.Expr{}, err
+	}
+	return clause.Expr{SQL: "CURRENT_TIMESTAMP"}, nil
+}
+
+func (e nowExpression) evaluate(field *schema.Field, current interface{}) (interface{}, error) {
+	if err := e.validate(field); err != nil {
+		return nil, err
+	}
+	return currentTime(field), nil
+}
+
+// currentTime returns current time truncated to precision of time column
+func currentTime(field *schema.Field) time.Time {
+	return time.Now().Truncate(timePrecision(field))
+}
+
+// timePrecision returns precision of time column, the database drops
+// fractional seconds beyond it. Microseconds are assumed unless precision
+// is given by gorm tag.
+func timePrecision(field *schema.Field) time.Duration {
+	precision := 6
+	if _, ok := field.TagSettings["PRECISION"]; ok && field.Precision < precision {
+		precision = field.Precision
+	}
+	duration := time.Second
+	for i := 0; i < precision; i++ {
+		duration /= 10
+	}
+	return duration
+}
+
+// updateValues returns update values with update expressions converted to
+// SQL expressions, other values are left as they are
+func updateValues(modelSchema *schema.Schema, values map[string]any) (map[string]any, error) {
+	converted := make(map[string]any, len(values))
+	for column, value := range values {
+		expression, ok := value.(UpdateExpression)
+		if !ok {
+			converted[column] = value
+			continue
+		}
+
+		field, err := updateField(modelSchema, column)
+		if err != nil {
+			return nil, err
+		}
+		converted[field.DBName], err = expression.expression(field)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return converted, nil
+}
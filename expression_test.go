@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Counter struct {
+	Id     uint
+	Hits   int
+	Score  float64
+	Label  *string
+	SeenAt *time.Time
+}
+
+func (m Counter) TableName() string {
+	return "counters"
+}
+
+type CounterFilter struct {
+	Id *uint `filterfield:"field=id;operator=EQ"`
+}
+
+func TestUpdateExpressions(t *testing.T) {
+	sqldb, db, _ := NewMockDB()
+	defer sqldb.Close()
+
+	repo := RepoBase[Counter]{}
+	repo.Init(db, nil)
+	id := uint(1)
+	filter := CounterFilter{Id: &id}
+
+	t.Run("Atomic updates", func(t *testing.T) {
+		statement, err := repo.ToSQL().Update(filter, map[string]any{
+			"hits":    Increment(2),
+			"Score":   Decrement(0.5),
+			"label":   Coalesce("default"),
+			"seen_at": SetNow(),
+		})
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"UPDATE counters SET hits=hits + $1,label=COALESCE(label, $2),score=score - $3,seen_at=CURRENT_TIMESTAMP WHERE counters.id = $4",
+			statement.SQL,
+		)
+		assert.Equal(t, []interface{}{2, "default", 0.5, uint64(id)}, statement.Vars)
+
+		statement, err = repo.ToSQL().Update(filter, map[string]any{"hits": SetExpr("hits * ?", 2), "score": 1.5})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE counters SET hits=hits * $1,score=$2 WHERE counters.id = $3", statement.SQL)
+	})
+
+	t.Run("Column types are validated", func(t *testing.T) {
+		for _, testCase := range []struct {
+			values   map[string]any
+			expected string
+		}{
+			{map[string]any{"label": Increment(1)}, "column is not numeric: label"},
+			{map[string]any{"hits": Increment(0.5)}, "invalid increment of integer column hits: float64"},
+			{map[string]any{"score": Decrement("1")}, "invalid increment of float column score: string"},
+			{map[string]any{"hits": SetNow()}, "column is not a time: hits"},
+			{map[string]any{"label": Coalesce(1)}, "invalid type for column label: int, expected string"},
+			{map[string]any{"unknown": Increment(1)}, "unknown column: unknown"},
+		} {
+			_, err := repo.ToSQL().Update(filter, testCase.values)
+			assert.EqualError(t, err, testCase.expected)
+		}
+	})
+
+	t.Run("Memory repository", func(t *testing.T) {
+		memory := MemoryRepo[Counter]{}
+		memory.Init(nil)
+		_, err := memory.Save(&Counter{Id: id, Hits: 1, Score: 2})
+		assert.Nil(t, err)
+
+		_, err = memory.Update(filter, map[string]any{
+			"hits":    Increment(2),
+			"score":   Decrement(0.5),
+			"label":   Coalesce("default"),
+			"seen_at": SetNow(),
+		})
+		assert.Nil(t, err)
+		model, err := memory.Get(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, model.Hits)
+		assert.Equal(t, 1.5, model.Score)
+		assert.Equal(t, "default", *model.Label)
+		assert.NotNil(t, model.SeenAt)
+
+		_, err = memory.Update(filter, map[string]any{"hits": SetExpr("hits * 2")})
+		assert.EqualError(t, err, "sql expression can't be evaluated in memory: hits")
+	})
+}
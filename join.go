@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edkirin/gormfilterrepo/smartfilter"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type JoinType string
+
+const (
+	JoinInner JoinType = "INNER"
+	JoinLeft  JoinType = "LEFT"
+)
+
+// Join joins associated table of model. Association is a path of
+// association field names, e.g. "Items.Product", association along the
+// path must be joined by preceding Join. Filter fields target joined table
+// by "table" tag option, set to Alias or association table name.
+type Join struct {
+	Association string
+	// Type defaults to inner join
+	Type JoinType
+	// Alias defaults to association table name
+	Alias string
+}
+
+// joinCondition returns ON condition of relationship, left is the table
+// name or alias of parent model
+func joinCondition(relationship *schema.Relationship, left string, alias string) (clause.Expression, error) {
+	if relationship.JoinTable != nil {
+		return nil, fmt.Errorf("many to many associations can't be joined")
+	}
+
+	conditions := make([]clause.Expression, 0, len(relationship.References))
+	for _, reference := range relationship.References {
+		switch {
+		case len(reference.PrimaryValue) > 0:
+			conditions = append(conditions, clause.Eq{
+				Column: clause.Column{Table: alias, Name: reference.ForeignKey.DBName},
+				Value:  reference.PrimaryValue,
+			})
+		case reference.OwnPrimaryKey:
+			conditions = append(conditions, clause.Eq{
+				Column: clause.Column{Table: alias, Name: reference.ForeignKey.DBName},
+				Value:  clause.Column{Table: left, Name: reference.PrimaryKey.DBName},
+			})
+		default:
+			conditions = append(conditions, clause.Eq{
+				Column: clause.Column{Table: alias, Name: reference.PrimaryKey.DBName},
+				Value:  clause.Column{Table: left, Name: reference.ForeignKey.DBName},
+			})
+		}
+	}
+	return clause.And(conditions...), nil
+}
+
+// ApplyJoinAssociations joins associated tables of model, associations are
+// validated against model relationships. Only columns of model are selected,
+// columns already selected by ApplyOptionOnly are qualified with model table.
+func ApplyJoinAssociations(query *gorm.DB, model schema.Tabler, joins []Join) *gorm.DB {
+	if len(joins) == 0 {
+		return query
+	}
+
+	modelSchema, err := parseSchema(query, model)
+	if err != nil {
+		return smartfilter.WithError(query, err)
+	}
+
+	// table names or aliases of joined associations
+	aliases := map[string]string{"": model.TableName()}
+	for _, join := range joins {
+		parent := ""
+		if idx := strings.LastIndex(join.Association, ASSOCIATION_PATH_SEPARATOR); idx >= 0 {
+			parent = join.Association[:idx]
+		}
+		left, ok := aliases[parent]
+		if !ok {
+			return smartfilter.WithError(query, fmt.Errorf("%s: association must be joined before: %s", join.Association, parent))
+		}
+		if _, ok := aliases[join.Association]; ok {
+			return smartfilter.WithError(query, fmt.Errorf("%s: association is already joined", join.Association))
+		}
+
+		relationship, err := lookUpAssociation(modelSchema, join.Association)
+		if err != nil {
+			return smartfilter.WithError(query, err)
+		}
+
+		joinType := join.Type
+		switch joinType {
+		case "":
+			joinType = JoinInner
+		case JoinInner, JoinLeft:
+		default:
+			return smartfilter.WithError(query, fmt.Errorf("%s: invalid join type: %s", join.Association, join.Type))
+		}
+
+		alias := join.Alias
+		if len(alias) == 0 {
+			alias = relationship.FieldSchema.Table
+		} else if !smartfilter.IsIdentifier(alias) {
+			return smartfilter.WithError(query, fmt.Errorf("%s: invalid join alias: %s", join.Association, alias))
+		}
+		for _, used := range aliases {
+			if used == alias {
+				return smartfilter.WithError(query, fmt.Errorf("%s: table or alias is already used: %s", join.Association, alias))
+			}
+		}
+
+		condition, err := joinCondition(relationship, left, alias)
+		if err != nil {
+			return smartfilter.WithError(query, fmt.Errorf("%s: %s", join.Association, err))
+		}
+
+		table := clause.Table{Name: relationship.FieldSchema.Table}
+		if alias != table.Name {
+			table.Alias = alias
+		}
+		query = query.Joins(fmt.Sprintf("%s JOIN ? ON ?", joinType), table, condition)
+		aliases[join.Association] = alias
+	}
+
+	// joined columns would overwrite model columns of the same name
+	if len(query.Statement.Selects) == 0 {
+		return query.Select("?.*", clause.Table{Name: model.TableName()})
+	}
+	selects := make([]string, 0, len(query.Statement.Selects))
+	for _, column := range query.Statement.Selects {
+		if field := modelSchema.LookUpField(column); field != nil && len(field.DBName) > 0 {
+			column = query.Statement.Quote(clause.Column{Table: model.TableName(), Name: field.DBName})
+		}
+		selects = append(selects, column)
+	}
+	return query.Select(selects)
+}
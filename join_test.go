@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Customer struct {
+	Id   uint
+	Name string
+}
+
+func (m Customer) TableName() string {
+	return "customers"
+}
+
+type PurchaseOrderFilter struct {
+	CustomerName *string `filterfield:"field=name;operator=EQ;table=customers"`
+	ProductName  *string `filterfield:"field=name;operator=EQ;table=p"`
+	QtyGT        *int    `filterfield:"field=qty;operator=GT;table=order_items"`
+}
+
+func TestJoinAssociations(t *testing.T) {
+	sqldb, db, _ := NewMockDB()
+	defer sqldb.Close()
+
+	repo := RepoBase[PurchaseOrder]{}
+	repo.Init(db, nil)
+
+	t.Run("Join associations", func(t *testing.T) {
+		name := "pencil"
+		qty := 2
+		statement, err := repo.ToSQL().List(PurchaseOrderFilter{ProductName: &name, QtyGT: &qty}, &ListOptions{
+			JoinAssociations: []Join{
+				{Association: "Customer", Type: JoinLeft},
+				{Association: "Items"},
+				{Association: "Items.Product", Alias: "p"},
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT orders.* FROM orders "+
+				"LEFT JOIN customers ON customers.id = orders.customer_id "+
+				"INNER JOIN order_items ON order_items.order_id = orders.id "+
+				"INNER JOIN products p ON p.id = order_items.product_id "+
+				"WHERE p.name = $1 AND order_items.qty > $2",
+			statement.SQL,
+		)
+		assert.Equal(t, []interface{}{name, int64(qty)}, statement.Vars)
+	})
+
+	t.Run("Only columns are qualified with model table", func(t *testing.T) {
+		name := "ACME"
+		statement, err := repo.ToSQL().Get(PurchaseOrderFilter{CustomerName: &name}, &GetOptions{
+			Only:             []string{"id", "CustomerId", "customers.name"},
+			JoinAssociations: []Join{{Association: "Customer"}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT orders.id,orders.customer_id,customers.name FROM orders "+
+				"INNER JOIN customers ON customers.id = orders.customer_id "+
+				"WHERE customers.name = $1 ORDER BY orders.id LIMIT $2",
+			statement.SQL,
+		)
+
+		statement, err = repo.ToSQL().List(nil, &ListOptions{
+			Only:             []string{"id"},
+			JoinAssociations: []Join{{Association: "Customer"}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT orders.id FROM orders INNER JOIN customers ON customers.id = orders.customer_id",
+			statement.SQL,
+		)
+	})
+
+	t.Run("Fail on invalid join", func(t *testing.T) {
+		for _, c := range []struct {
+			joins []Join
+			err   string
+		}{
+			{[]Join{{Association: "Vendor"}}, "unknown association: Vendor"},
+			{[]Join{{Association: "Items.Product"}}, "Items.Product: association must be joined before: Items"},
+			{[]Join{{Association: "Customer"}, {Association: "Customer"}}, "Customer: association is already joined"},
+			{[]Join{{Association: "Customer", Type: "CROSS"}}, "Customer: invalid join type: CROSS"},
+			{[]Join{{Association: "Customer", Alias: "c; --"}}, "Customer: invalid join alias: c; --"},
+			{[]Join{{Association: "Customer", Alias: "orders"}}, "Customer: table or alias is already used: orders"},
+		} {
+			_, err := repo.List(nil, &ListOptions{JoinAssociations: c.joins})
+			assert.EqualError(t, err, c.err)
+		}
+	})
+}
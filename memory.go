@@ -0,0 +1,727 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/edkirin/gormfilterrepo/smartfilter"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var memorySchemaCache = sync.Map{}
+
+// MemoryRepo is an in-memory implementation of Repository, meant for unit
+// tests. Models are stored in a map keyed by IdField, filters, ordering and
+// pagination are evaluated in Go. Models are stored as shallow copies.
+//
+// Missing ids are generated on Save for uuid and integer id fields.
+// Only filter operators supported by smartfilter.Matches can be used.
+type MemoryRepo[T schema.Tabler] struct {
+	IdField         string
+	DefaultOrdering []Order
+	MaxRowsAffected int64
+	VersionField    string
+	PreSave         func(model *T) error
+	PostSave        func(model *T) error
+	PreUpdate       func(values map[string]any) error
+	PostUpdate      func(values map[string]any) error
+
+	mutex   sync.RWMutex
+	models  map[interface{}]T
+	keys    []interface{}
+	counter int64
+}
+
+func (m *MemoryRepo[T]) Init(options *RepoOptions) {
+	m.IdField = DEFAULT_ID_FIELD
+	m.DefaultOrdering = nil
+	m.MaxRowsAffected = 0
+	m.VersionField = ""
+	if options != nil {
+		if len(options.IdField) > 0 {
+			m.IdField = options.IdField
+		}
+		m.DefaultOrdering = options.DefaultOrdering
+		m.MaxRowsAffected = options.MaxRowsAffected
+		m.VersionField = options.VersionField
+	}
+	m.models = map[interface{}]T{}
+	m.keys = nil
+	m.counter = 0
+}
+
+func (m *MemoryRepo[T]) schema() (*schema.Schema, error) {
+	var model T
+	return schema.Parse(&model, &memorySchemaCache, schema.NamingStrategy{})
+}
+
+func (m *MemoryRepo[T]) lookUpField(column string) (*schema.Field, error) {
+	modelSchema, err := m.schema()
+	if err != nil {
+		return nil, err
+	}
+	field := modelSchema.LookUpField(column)
+	if field == nil || len(field.DBName) == 0 {
+		return nil, fmt.Errorf("unknown column: %s", column)
+	}
+	return field, nil
+}
+
+// versionField returns version field of model, nil if model isn't versioned
+func (m *MemoryRepo[T]) versionField() (*schema.Field, error) {
+	modelSchema, err := m.schema()
+	if err != nil {
+		return nil, err
+	}
+	return lookUpVersionField(modelSchema, m.VersionField)
+}
+
+// columnValue converts value to column value of field, the same way as
+// values of stored models are converted
+func (m *MemoryRepo[T]) columnValue(field *schema.Field, value interface{}) (interface{}, error) {
+	var model T
+	err := field.Set(context.Background(), reflect.ValueOf(&model).Elem(), value)
+	if err != nil {
+		return nil, err
+	}
+	return smartfilter.ColumnValue(model, field.DBName)
+}
+
+// sameVersion reports whether model has expected version
+func (m *MemoryRepo[T]) sameVersion(model T, field *schema.Field, expected interface{}) (bool, error) {
+	version, err := smartfilter.ColumnValue(model, field.DBName)
+	if err != nil {
+		return false, err
+	}
+	cmp, err := smartfilter.CompareValues(version, expected)
+	return cmp == 0, err
+}
+
+// withVersion returns models having expected version
+func (m *MemoryRepo[T]) withVersion(models []T, field *schema.Field, expected interface{}) ([]T, error) {
+	versioned := make([]T, 0, len(models))
+	for _, model := range models {
+		same, err := m.sameVersion(model, field, expected)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			versioned = append(versioned, model)
+		}
+	}
+	return versioned, nil
+}
+
+// filtered returns all stored models matching filter, in insertion order
+func (m *MemoryRepo[T]) filtered(filter interface{}) ([]T, error) {
+	models := make([]T, 0)
+	for _, key := range m.keys {
+		model := m.models[key]
+		if filter != nil {
+			matches, err := smartfilter.Matches(model, filter)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+// sorted orders models the same way as database would, NULLs are greater
+// than other values unless Nulls option is set
+func (m *MemoryRepo[T]) sorted(models []T, ordering []Order) ([]T, error) {
+	for _, order := range ordering {
+		if len(order.Table) > 0 || len(order.Expression) > 0 || len(order.Collate) > 0 {
+			return nil, fmt.Errorf("only field ordering is supported by memory repository")
+		}
+		if _, err := m.lookUpField(order.Field); err != nil {
+			return nil, err
+		}
+	}
+
+	var sortErr error
+	slices.SortStableFunc(models, func(a T, b T) int {
+		for _, order := range ordering {
+			valueA, errA := smartfilter.ColumnValue(a, order.Field)
+			valueB, errB := smartfilter.ColumnValue(b, order.Field)
+			c, err := smartfilter.CompareValues(valueA, valueB)
+			for _, e := range []error{errA, errB, err} {
+				if e != nil && sortErr == nil {
+					sortErr = e
+				}
+			}
+			if c == 0 {
+				continue
+			}
+			if order.Nulls == OrderNullsFirst && (valueA == nil || valueB == nil) {
+				return -c
+			}
+			if order.Nulls == OrderNullsLast && (valueA == nil || valueB == nil) {
+				return c
+			}
+			if order.Direction == OrderDESC {
+				return -c
+			}
+			return c
+		}
+		return 0
+	})
+	return models, sortErr
+}
+
+// selected returns copies of models with only given columns set
+func (m *MemoryRepo[T]) selected(models []T, only []string) ([]T, error) {
+	if len(only) == 0 {
+		return models, nil
+	}
+
+	fields := make([]*schema.Field, 0, len(only))
+	for _, column := range only {
+		field, err := m.lookUpField(column)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	ctx := context.Background()
+	res := make([]T, 0, len(models))
+	for _, model := range models {
+		var selected T
+		source := reflect.ValueOf(&model).Elem()
+		target := reflect.ValueOf(&selected).Elem()
+		for _, field := range fields {
+			field.ReflectValueOf(ctx, target).Set(field.ReflectValueOf(ctx, source))
+		}
+		res = append(res, selected)
+	}
+	return res, nil
+}
+
+func paginated[T any](models []T, pagination *Pagination) []T {
+	if pagination == nil {
+		return models
+	}
+	if pagination.Offset > 0 {
+		models = models[min(pagination.Offset, len(models)):]
+	}
+	if pagination.Limit > 0 {
+		models = models[:min(pagination.Limit, len(models))]
+	}
+	return models
+}
+
+func (m *MemoryRepo[T]) List(filter interface{}, options *ListOptions) (*[]T, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	models, err := m.filtered(filter)
+	if err != nil {
+		return nil, err
+	}
+	var ordering []Order
+	if options != nil {
+		if len(options.Joins) > 0 || len(options.JoinAssociations) > 0 {
+			return nil, fmt.Errorf("joins are not supported by memory repository")
+		}
+		if len(options.Preload) > 0 {
+			return nil, fmt.Errorf("preload is not supported by memory repository")
+		}
+		ordering = options.Ordering
+	}
+	ordering, err = resolveOrdering(filter, ordering, m.DefaultOrdering)
+	if err != nil {
+		return nil, err
+	}
+	models, err = m.sorted(models, ordering)
+	if err != nil {
+		return nil, err
+	}
+
+	if options != nil {
+		models = paginated(models, options.Pagination)
+		models, err = m.selected(models, options.Only)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &models, nil
+}
+
+func (m *MemoryRepo[T]) Get(filter interface{}, options *GetOptions) (*T, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	models, err := m.filtered(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var ordering []Order
+	if options != nil {
+		if len(options.Joins) > 0 || len(options.JoinAssociations) > 0 {
+			return nil, fmt.Errorf("joins are not supported by memory repository")
+		}
+		if len(options.Preload) > 0 {
+			return nil, fmt.Errorf("preload is not supported by memory repository")
+		}
+		ordering = options.Ordering
+	}
+	ordering, err = resolveOrdering(filter, ordering, m.DefaultOrdering)
+	if err != nil {
+		return nil, err
+	}
+	// primary key is appended as the final tie-breaker, as in RepoBase.Get
+	ordering = append(slices.Clip(ordering), Order{Field: m.IdField})
+	models, err = m.sorted(models, ordering)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(models) == 0 {
+		if options != nil && options.RaiseError {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, nil
+	}
+
+	if options != nil {
+		models, err = m.selected(models[:1], options.Only)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &models[0], nil
+}
+
+func (m *MemoryRepo[T]) Exists(filter interface{}) (bool, error) {
+	count, err := m.Count(filter)
+	return count > 0, err
+}
+
+func (m *MemoryRepo[T]) Count(filter interface{}) (int64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	models, err := m.filtered(filter)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(models)), nil
+}
+
+// modelKey returns id field value of model, used as map key. Nil is
+// returned for empty id.
+func (m *MemoryRepo[T]) modelKey(model *T) (interface{}, error) {
+	field, err := m.lookUpField(m.IdField)
+	if err != nil {
+		return nil, err
+	}
+
+	value := field.ReflectValueOf(context.Background(), reflect.ValueOf(model).Elem())
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if value.IsZero() {
+		return nil, nil
+	}
+	if !value.Type().Comparable() {
+		return nil, fmt.Errorf("id field type is not comparable: %v", value.Type())
+	}
+	return value.Interface(), nil
+}
+
+// generateKey sets new id on model, uuid ids are random and integer ids
+// are incremented
+func (m *MemoryRepo[T]) generateKey(model *T) (interface{}, error) {
+	field, err := m.lookUpField(m.IdField)
+	if err != nil {
+		return nil, err
+	}
+
+	value := field.ReflectValueOf(context.Background(), reflect.ValueOf(model).Elem())
+	if value.Kind() == reflect.Pointer {
+		value.Set(reflect.New(value.Type().Elem()))
+		value = value.Elem()
+	}
+
+	switch {
+	case value.Type() == reflect.TypeOf(uuid.UUID{}):
+		value.Set(reflect.ValueOf(uuid.New()))
+	case value.CanInt():
+		m.counter++
+		value.SetInt(m.counter)
+	case value.CanUint():
+		m.counter++
+		value.SetUint(uint64(m.counter))
+	default:
+		return nil, fmt.Errorf("can't generate value for id field type %v", value.Type())
+	}
+	return value.Interface(), nil
+}
+
+func (m *MemoryRepo[T]) store(key interface{}, model T) {
+	if m.models == nil {
+		m.models = map[interface{}]T{}
+	}
+	if _, ok := m.models[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.models[key] = model
+
+	// keep generated integer ids unique
+	value := reflect.ValueOf(key)
+	if value.CanInt() && value.Int() > m.counter {
+		m.counter = value.Int()
+	}
+	if value.CanUint() && int64(value.Uint()) > m.counter {
+		m.counter = int64(value.Uint())
+	}
+}
+
+func (m *MemoryRepo[T]) Save(model *T) (*T, error) {
+	if m.PreSave != nil {
+		err := m.PreSave(model)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.mutex.Lock()
+	err := m.save(model)
+	m.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.PostSave != nil {
+		err := m.PostSave(model)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return model, nil
+}
+
+// save stores model, checking and bumping its version the same way as
+// RepoBase does
+func (m *MemoryRepo[T]) save(model *T) error {
+	field, err := m.versionField()
+	if err != nil {
+		return err
+	}
+	key, err := m.modelKey(model)
+	if err != nil {
+		return err
+	}
+
+	if field != nil {
+		modelValue := reflect.ValueOf(model).Elem()
+		expected, err := smartfilter.ColumnValue(*model, field.DBName)
+		if err != nil {
+			return err
+		}
+		previous, isZero, err := bumpVersion(field, modelValue)
+		if err != nil {
+			return err
+		}
+		if !isZero {
+			same := false
+			if stored, ok := m.models[key]; ok {
+				same, err = m.sameVersion(stored, field, expected)
+			}
+			if err == nil && !same {
+				err = ErrStaleObject
+			}
+			if err != nil {
+				_ = field.Set(context.Background(), modelValue, previous)
+				return err
+			}
+		}
+	}
+
+	if key == nil {
+		key, err = m.generateKey(model)
+		if err != nil {
+			return err
+		}
+	}
+	m.store(key, *model)
+	return nil
+}
+
+// SaveReturning saves model, stored model is the same as given one
+func (m *MemoryRepo[T]) SaveReturning(model *T, options *ReturningOptions) (*T, error) {
+	for _, column := range returningOnly(options) {
+		if _, err := m.lookUpField(column); err != nil {
+			return nil, err
+		}
+	}
+	return m.Save(model)
+}
+
+func (m *MemoryRepo[T]) Update(filter interface{}, values map[string]any) (int64, error) {
+	models, err := m.updateWithHooks(filter, values, false)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(models)), nil
+}
+
+func (m *MemoryRepo[T]) UpdateReturning(filter interface{}, values map[string]any, options *ReturningOptions) (*[]T, error) {
+	models, err := m.updateWithHooks(filter, values, false)
+	if err != nil {
+		return nil, err
+	}
+	models, err = m.selected(models, returningOnly(options))
+	if err != nil {
+		return nil, err
+	}
+	return &models, nil
+}
+
+func (m *MemoryRepo[T]) UpdateAll(values map[string]any) (int64, error) {
+	models, err := m.updateWithHooks(nil, values, true)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(models)), nil
+}
+
+func (m *MemoryRepo[T]) updateWithHooks(filter interface{}, values map[string]any, all bool) ([]T, error) {
+	if m.PreUpdate != nil {
+		err := m.PreUpdate(values)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	models, err := m.update(filter, values, all)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.PostUpdate != nil {
+		err := m.PostUpdate(values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+func (m *MemoryRepo[T]) UpdatePatch(filter interface{}, patch interface{}) (int64, error) {
+	modelSchema, err := m.schema()
+	if err != nil {
+		return 0, err
+	}
+	values, err := patchValues(modelSchema, patch)
+	if err != nil {
+		return 0, err
+	}
+	return m.Update(filter, values)
+}
+
+func (m *MemoryRepo[T]) UpdateFields(filter interface{}, model *T, fields []string) (int64, error) {
+	modelSchema, err := m.schema()
+	if err != nil {
+		return 0, err
+	}
+	values, err := fieldMaskValues(modelSchema, model, fields)
+	if err != nil {
+		return 0, err
+	}
+	return m.Update(filter, values)
+}
+
+// update sets values on models matching filter and returns updated models
+func (m *MemoryRepo[T]) update(filter interface{}, values map[string]any, all bool) ([]T, error) {
+	if !all {
+		if err := checkFilter(filter); err != nil {
+			return nil, err
+		}
+	}
+
+	versionField, err := m.versionField()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		version      interface{}
+		checkVersion bool
+	)
+	if versionField != nil {
+		values, version, checkVersion = versionValues(versionField, values)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	fields := map[*schema.Field]any{}
+	for column, value := range values {
+		field, err := m.lookUpField(column)
+		if err != nil {
+			return nil, err
+		}
+		// expressions are validated before any model is updated
+		if expression, ok := value.(UpdateExpression); ok {
+			if _, err := expression.evaluate(field, nil); err != nil {
+				return nil, err
+			}
+		}
+		fields[field] = value
+	}
+
+	models, err := m.filtered(filter)
+	if err != nil {
+		return nil, err
+	}
+	if checkVersion {
+		version, err = m.columnValue(versionField, version)
+		if err != nil {
+			return nil, err
+		}
+		models, err = m.withVersion(models, versionField, version)
+		if err != nil {
+			return nil, err
+		}
+		if len(models) == 0 {
+			return nil, ErrStaleObject
+		}
+	}
+	if err := checkRowsAffected(int64(len(models)), m.MaxRowsAffected); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	keys := make([]interface{}, len(models))
+	newKeys := make([]interface{}, len(models))
+	for i := range models {
+		model := &models[i]
+		keys[i], err = m.modelKey(model)
+		if err != nil {
+			return nil, err
+		}
+		for field, value := range fields {
+			if expression, ok := value.(UpdateExpression); ok {
+				current, _ := field.ValueOf(ctx, reflect.ValueOf(model).Elem())
+				value, err = expression.evaluate(field, current)
+				if err != nil {
+					return nil, err
+				}
+			}
+			err := field.Set(ctx, reflect.ValueOf(model).Elem(), value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// id may be updated as well
+		newKeys[i], err = m.modelKey(model)
+		if err != nil {
+			return nil, err
+		}
+		if newKeys[i] == nil {
+			return nil, fmt.Errorf("id field can't be empty")
+		}
+	}
+
+	// models are stored only if new ids don't collide with each other or
+	// with ids of models which aren't updated
+	updated := map[interface{}]bool{}
+	for _, key := range keys {
+		updated[key] = true
+	}
+	seen := map[interface{}]bool{}
+	for _, newKey := range newKeys {
+		_, stored := m.models[newKey]
+		if seen[newKey] || (stored && !updated[newKey]) {
+			return nil, fmt.Errorf("id already exists: %v", newKey)
+		}
+		seen[newKey] = true
+	}
+
+	indexes := make([]int, len(keys))
+	for i, key := range keys {
+		indexes[i] = slices.Index(m.keys, key)
+		delete(m.models, key)
+	}
+	for i, model := range models {
+		m.keys[indexes[i]] = newKeys[i]
+		m.models[newKeys[i]] = model
+	}
+	return models, nil
+}
+
+func (m *MemoryRepo[T]) Delete(filter interface{}) (int64, error) {
+	models, err := m.delete(filter, false)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(models)), nil
+}
+
+func (m *MemoryRepo[T]) DeleteReturning(filter interface{}, options *ReturningOptions) (*[]T, error) {
+	models, err := m.delete(filter, false)
+	if err != nil {
+		return nil, err
+	}
+	models, err = m.selected(models, returningOnly(options))
+	if err != nil {
+		return nil, err
+	}
+	return &models, nil
+}
+
+func (m *MemoryRepo[T]) DeleteAll() (int64, error) {
+	models, err := m.delete(nil, true)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(models)), nil
+}
+
+// delete removes models matching filter and returns them
+func (m *MemoryRepo[T]) delete(filter interface{}, all bool) ([]T, error) {
+	if !all {
+		if err := checkFilter(filter); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	models, err := m.filtered(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRowsAffected(int64(len(models)), m.MaxRowsAffected); err != nil {
+		return nil, err
+	}
+
+	for _, model := range models {
+		key, err := m.modelKey(&model)
+		if err != nil {
+			return nil, err
+		}
+		delete(m.models, key)
+	}
+	m.keys = slices.DeleteFunc(m.keys, func(key interface{}) bool {
+		_, ok := m.models[key]
+		return !ok
+	})
+	return models, nil
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/edkirin/gormfilterrepo/smartfilter"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type MyIntModel struct {
+	Id    uint
+	Value string
+}
+
+func (m MyIntModel) TableName() string {
+	return "my_int_models"
+}
+
+func newMemoryRepo(t *testing.T) (*MemoryRepo[MyModel], []MyModel) {
+	repo := MemoryRepo[MyModel]{}
+	repo.Init(nil)
+
+	models := []MyModel{}
+	for _, m := range []MyModel{
+		{Value: "first", Cnt: 3},
+		{Value: "second", Cnt: 1},
+		{Value: "third", Cnt: 2},
+	} {
+		saved, err := repo.Save(&m)
+		assert.Nil(t, err)
+		models = append(models, *saved)
+	}
+	return &repo, models
+}
+
+func TestMemoryRepo(t *testing.T) {
+	t.Run("Save generates ids", func(t *testing.T) {
+		_, models := newMemoryRepo(t)
+		for _, model := range models {
+			assert.NotNil(t, model.Id)
+		}
+
+		repo := MemoryRepo[MyIntModel]{}
+		repo.Init(nil)
+		for _, expected := range []uint{1, 2} {
+			model, err := repo.Save(&MyIntModel{Value: "x"})
+			assert.Nil(t, err)
+			assert.Equal(t, expected, model.Id)
+		}
+		model, err := repo.Save(&MyIntModel{Id: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, uint(10), model.Id)
+		model, err = repo.Save(&MyIntModel{})
+		assert.Nil(t, err)
+		assert.Equal(t, uint(11), model.Id)
+	})
+
+	t.Run("Save updates existing model", func(t *testing.T) {
+		repo, models := newMemoryRepo(t)
+
+		model := models[1]
+		model.Value = "changed"
+		_, err := repo.Save(&model)
+		assert.Nil(t, err)
+
+		count, err := repo.Count(MyModelFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+
+		value := "changed"
+		result, err := repo.Get(MyModelFilter{Value: &value}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models[1].Id, result.Id)
+	})
+
+	t.Run("Save hooks", func(t *testing.T) {
+		repo := MemoryRepo[MyModel]{}
+		repo.Init(nil)
+
+		calls := []string{}
+		repo.PreSave = func(model *MyModel) error {
+			calls = append(calls, "pre")
+			return nil
+		}
+		repo.PostSave = func(model *MyModel) error {
+			calls = append(calls, "post")
+			return nil
+		}
+		_, err := repo.Save(&MyModel{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"pre", "post"}, calls)
+
+		repo.PreSave = func(model *MyModel) error {
+			return errors.New("invalid model")
+		}
+		_, err = repo.Save(&MyModel{})
+		assert.EqualError(t, err, "invalid model")
+
+		count, _ := repo.Count(MyModelFilter{})
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("List with filter, ordering and pagination", func(t *testing.T) {
+		repo, _ := newMemoryRepo(t)
+
+		cnt := 1
+		result, err := repo.List(MyModelFilter{CntGT: &cnt}, &ListOptions{
+			Ordering: []Order{{Field: "cnt", Direction: OrderDESC}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(*result))
+		assert.Equal(t, "first", (*result)[0].Value)
+		assert.Equal(t, "third", (*result)[1].Value)
+
+		result, err = repo.List(MyModelFilter{}, &ListOptions{
+			Ordering:   []Order{{Field: "cnt"}},
+			Pagination: &Pagination{Offset: 1, Limit: 1},
+			Only:       []string{"value"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []MyModel{{Value: "third"}}, *result)
+
+		result, err = repo.List(MyModelFilter{}, &ListOptions{Pagination: &Pagination{Offset: 10}})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(*result))
+
+		_, err = repo.List(MyModelFilter{}, &ListOptions{Ordering: []Order{{Field: "unknown"}}})
+		assert.EqualError(t, err, "unknown column: unknown")
+	})
+
+	t.Run("List with runtime filter", func(t *testing.T) {
+		repo, _ := newMemoryRepo(t)
+
+		result, err := repo.List(
+			smartfilter.New().Where("value", smartfilter.OperatorIN, []string{"first", "second"}).Not("cnt", smartfilter.OperatorEQ, 1),
+			nil,
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(*result))
+		assert.Equal(t, "first", (*result)[0].Value)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		repo, models := newMemoryRepo(t)
+
+		result, err := repo.Get(MyModelFilter{Id: models[2].Id}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models[2], *result)
+
+		result, err = repo.Get(MyModelFilter{}, &GetOptions{Ordering: []Order{{Field: "cnt"}}})
+		assert.Nil(t, err)
+		assert.Equal(t, "second", result.Value)
+
+		id := uuid.New()
+		result, err = repo.Get(MyModelFilter{Id: &id}, nil)
+		assert.Nil(t, result)
+		assert.Nil(t, err)
+
+		_, err = repo.Get(MyModelFilter{Id: &id}, &GetOptions{RaiseError: true})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Exists and count", func(t *testing.T) {
+		repo, models := newMemoryRepo(t)
+
+		exists, err := repo.Exists(MyModelFilter{Id: models[0].Id})
+		assert.Nil(t, err)
+		assert.True(t, exists)
+
+		id := uuid.New()
+		exists, err = repo.Exists(MyModelFilter{Id: &id})
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		count, err := repo.Count(MyModelFilter{Ids: &[]uuid.UUID{*models[0].Id, *models[1].Id, id}})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo, models := newMemoryRepo(t)
+
+		cnt := 1
+		updated, err := repo.Update(MyModelFilter{CntGT: &cnt}, map[string]any{"value": "updated", "cnt": 0})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), updated)
+
+		result, err := repo.List(MyModelFilter{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []MyModel{
+			{Id: models[0].Id, Value: "updated", Cnt: 0},
+			{Id: models[1].Id, Value: "second", Cnt: 1},
+			{Id: models[2].Id, Value: "updated", Cnt: 0},
+		}, *result)
+
+		_, err = repo.UpdateAll(map[string]any{"password": "x"})
+		assert.EqualError(t, err, "unknown column: password")
+	})
+
+	t.Run("Fail on updating id to existing one", func(t *testing.T) {
+		repo, models := newMemoryRepo(t)
+
+		_, err := repo.Update(MyModelFilter{Id: models[0].Id}, map[string]any{"id": models[1].Id})
+		assert.EqualError(t, err, fmt.Sprintf("id already exists: %v", *models[1].Id))
+
+		id := uuid.New()
+		_, err = repo.UpdateAll(map[string]any{"id": &id})
+		assert.EqualError(t, err, fmt.Sprintf("id already exists: %v", id))
+
+		result, err := repo.List(nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models, *result)
+
+		updated, err := repo.Update(MyModelFilter{Id: models[0].Id}, map[string]any{"id": &id})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), updated)
+		result, err = repo.List(nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(*result))
+		assert.Equal(t, id, *(*result)[0].Id)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo, models := newMemoryRepo(t)
+
+		deleted, err := repo.Delete(MyModelFilter{Id: models[1].Id})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		result, err := repo.List(MyModelFilter{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []MyModel{models[0], models[2]}, *result)
+	})
+
+	t.Run("Fail on unsupported operator", func(t *testing.T) {
+		repo, _ := newMemoryRepo(t)
+
+		search := "first"
+		_, err := repo.List(MyModelFilter{Search: &search}, nil)
+		assert.EqualError(t, err, "operator SEARCH can't be evaluated in memory")
+	})
+}
@@ -1,8 +1,7 @@
 package repository
 
 import (
-	"github.com/edkirin/gormfilterrepo/smartfilter"
-
+	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
 
@@ -15,17 +14,59 @@ func (m *DeleteMethod[T]) Init(repo *RepoBase[T]) {
 }
 
 func (m DeleteMethod[T]) Delete(filter interface{}) (int64, error) {
+	return m.delete(filter, false)
+}
+
+// DeleteAll deletes all models, filter is not required
+func (m DeleteMethod[T]) DeleteAll() (int64, error) {
+	return m.delete(nil, true)
+}
+
+func (m DeleteMethod[T]) delete(filter interface{}, all bool) (int64, error) {
+	var model T
+	return m.repo.limitRowsAffected(func(tx *gorm.DB) (int64, error) {
+		query, err := mutationQuery[T](tx, filter, all)
+		if err != nil {
+			return 0, err
+		}
+		result := query.Delete(&model)
+		return result.RowsAffected, result.Error
+	})
+}
+
+// DeleteReturning deletes models matching filter and returns them. MySQL
+// deletes models selected for update in a transaction.
+func (m DeleteMethod[T]) DeleteReturning(filter interface{}, options *ReturningOptions) (*[]T, error) {
 	var (
-		model T
+		model  T
+		models = []T{}
 	)
 
-	query, err := smartfilter.ToQuery(model, filter, m.repo.dbConn)
+	_, err := m.repo.limitRowsAffected(func(tx *gorm.DB) (int64, error) {
+		if supportsReturning(tx) {
+			query, err := mutationQuery[T](tx, filter, false)
+			if err != nil {
+				return 0, err
+			}
+			err = query.Clauses(returningClause(options)).Delete(&models).Error
+			return int64(len(models)), err
+		}
+
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			keys, err := lockedKeys[T](tx, m.repo.IdField, filter)
+			if err != nil || len(keys) == 0 {
+				return err
+			}
+			models, err = selectByKeys[T](tx, m.repo.IdField, keys, options)
+			if err != nil {
+				return err
+			}
+			return tx.Where(map[string]interface{}{m.repo.IdField: keys}).Delete(&model).Error
+		})
+		return int64(len(models)), err
+	})
 	if err != nil {
-		return 0, err
-	}
-	result := query.Delete(&model)
-	if result.Error != nil {
-		return 0, result.Error
+		return nil, err
 	}
-	return result.RowsAffected, nil
+	return &models, nil
 }
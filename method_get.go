@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"slices"
+
 	"github.com/edkirin/gormfilterrepo/smartfilter"
 
 	"gorm.io/gorm/schema"
@@ -10,7 +12,12 @@ type GetOptions struct {
 	Only       []string
 	Ordering   []Order
 	RaiseError bool
-	Joins      []string
+	// Joins are raw join clauses, JoinAssociations builds them from
+	// model associations
+	Joins            []string
+	JoinAssociations []Join
+	// Preload loads associations in separate queries
+	Preload []Preload
 }
 
 type GetMethod[T schema.Tabler] struct {
@@ -23,7 +30,8 @@ func (m *GetMethod[T]) Init(repo *RepoBase[T]) {
 
 func (m GetMethod[T]) Get(filter interface{}, options *GetOptions) (*T, error) {
 	var (
-		model T
+		model    T
+		ordering []Order
 	)
 
 	query, err := smartfilter.ToQuery(model, filter, m.repo.dbConn)
@@ -32,12 +40,31 @@ func (m GetMethod[T]) Get(filter interface{}, options *GetOptions) (*T, error) {
 	}
 
 	if options != nil {
+		ordering = options.Ordering
 		query = ApplyJoins(query, options.Joins)
 		query = ApplyOptionOnly(query, options.Only)
-		query = ApplyOptionOrdering(query, options.Ordering)
+		query = ApplyJoinAssociations(query, model, options.JoinAssociations)
+		query = ApplyPreload(query, model, options.Preload)
+	}
+	ordering, err = resolveOrdering(filter, ordering, m.repo.DefaultOrdering)
+	if err != nil {
+		return nil, err
+	}
+	// primary key is appended as the final tie-breaker, ordering merged
+	// by First would discard ordering expressions
+	modelSchema, err := parseSchema(query, &model)
+	if err != nil {
+		return nil, err
+	}
+	if primaryKey := modelSchema.PrioritizedPrimaryField; primaryKey != nil {
+		ordering = append(slices.Clip(ordering), Order{Table: model.TableName(), Field: primaryKey.DBName})
+	}
+	query = ApplyOptionOrdering(query, ordering)
+	if query.Error != nil {
+		return nil, query.Error
 	}
 
-	result := query.First(&model)
+	result := query.Take(&model)
 	if result.Error == nil {
 		return &model, nil
 	}
@@ -10,7 +10,15 @@ type ListOptions struct {
 	Only       []string
 	Ordering   []Order
 	Pagination *Pagination
-	Joins      []string
+	// Joins are raw join clauses, JoinAssociations builds them from
+	// model associations
+	Joins            []string
+	JoinAssociations []Join
+	// Preload loads associations in separate queries
+	Preload []Preload
+	// OrderBySearchRank orders results by relevance of SEARCH filter fields,
+	// before any other ordering
+	OrderBySearchRank bool
 }
 
 type ListMethod[T schema.Tabler] struct {
@@ -23,8 +31,9 @@ func (m *ListMethod[T]) Init(repo *RepoBase[T]) {
 
 func (m ListMethod[T]) List(filter interface{}, options *ListOptions) (*[]T, error) {
 	var (
-		model  T
-		models []T
+		model    T
+		models   []T
+		ordering []Order
 	)
 
 	query, err := smartfilter.ToQuery(model, filter, m.repo.dbConn)
@@ -32,12 +41,33 @@ func (m ListMethod[T]) List(filter interface{}, options *ListOptions) (*[]T, err
 		return nil, err
 	}
 
+	if options != nil {
+		ordering = options.Ordering
+	}
+	ordering, err = resolveOrdering(filter, ordering, m.repo.DefaultOrdering)
+	if err != nil {
+		return nil, err
+	}
+
 	if options != nil {
 		query = ApplyJoins(query, options.Joins)
 		query = ApplyOptionOnly(query, options.Only)
-		query = ApplyOptionOrdering(query, options.Ordering)
+		query = ApplyJoinAssociations(query, model, options.JoinAssociations)
+		query = ApplyPreload(query, model, options.Preload)
+		if options.OrderBySearchRank {
+			query, err = smartfilter.ApplySearchRank(model, filter, query)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	query = ApplyOptionOrdering(query, ordering)
+	if options != nil {
 		query = ApplyOptionPagination(query, options.Pagination)
 	}
+	if query.Error != nil {
+		return nil, query.Error
+	}
 
 	query.Find(&models)
 	return &models, nil
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edkirin/gormfilterrepo/smartfilter"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/postgres"
@@ -43,10 +44,11 @@ func (m MyModel) TableName() string {
 }
 
 type MyModelFilter struct {
-	Id    *uuid.UUID   `filterfield:"field=id;operator=EQ"`
-	Ids   *[]uuid.UUID `filterfield:"field=id;operator=IN"`
-	Value *string      `filterfield:"field=value;operator=EQ"`
-	CntGT *int         `filterfield:"field=cnt;operator=GT"`
+	Id     *uuid.UUID   `filterfield:"field=id;operator=EQ"`
+	Ids    *[]uuid.UUID `filterfield:"field=id;operator=IN"`
+	Value  *string      `filterfield:"field=value;operator=EQ"`
+	CntGT  *int         `filterfield:"field=cnt;operator=GT"`
+	Search *string      `filterfield:"field=value;operator=SEARCH;config=english"`
 }
 
 func TestListMethod(t *testing.T) {
@@ -71,7 +73,7 @@ func TestListMethod(t *testing.T) {
 			},
 		}
 
-		sql := `SELECT * FROM my_models ORDER BY "id","cnt" DESC`
+		sql := `SELECT * FROM my_models ORDER BY id,cnt DESC`
 		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql)))
 
 		_, err := repo.List(filter, &options)
@@ -268,6 +270,60 @@ func TestListMethod(t *testing.T) {
 		_, err := repo.List(filter, &options)
 		assert.Nil(t, err)
 
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("Order by search rank", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		search := "quick fox"
+		filter := MyModelFilter{
+			Search: &search,
+		}
+		options := ListOptions{
+			OrderBySearchRank: true,
+			Ordering: []Order{
+				{
+					Field: "id",
+				},
+			},
+		}
+
+		sql := `SELECT * FROM my_models WHERE to_tsvector('english', my_models.value) @@ websearch_to_tsquery('english', $1) ORDER BY ts_rank(to_tsvector('english', my_models.value), websearch_to_tsquery('english', $2)) DESC,id`
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(search, search)
+
+		_, err := repo.List(filter, &options)
+		assert.Nil(t, err)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Runtime filter", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		filter := smartfilter.New().
+			Where("cnt", smartfilter.OperatorGT, 5).
+			Or("value", smartfilter.OperatorIN, []string{"a", "b"})
+
+		sql := "SELECT * FROM my_models WHERE (my_models.cnt > $1 OR my_models.value IN ($2,$3))"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(5, "a", "b")
+
+		_, err := repo.List(filter, nil)
+		assert.Nil(t, err)
+
 		if err := mock.ExpectationsWereMet(); err != nil {
 			t.Errorf("there were unfulfilled expectations: %s", err)
 		}
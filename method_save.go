@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
 
@@ -14,7 +15,9 @@ func (m *SaveMethod[T]) Init(repo *RepoBase[T]) {
 	m.repo = repo
 }
 
-func (m SaveMethod[T]) Save(model *T) (*T, error) {
+// withHooks runs save between save hooks, PostSave is run only if save
+// succeeds
+func (m SaveMethod[T]) withHooks(model *T, save func() error) (*T, error) {
 	if m.PreSave != nil {
 		err := m.PreSave(model)
 		if err != nil {
@@ -22,14 +25,48 @@ func (m SaveMethod[T]) Save(model *T) (*T, error) {
 		}
 	}
 
-	result := m.repo.dbConn.Save(model)
+	err := save()
+	if err != nil {
+		return nil, err
+	}
 
-	if result.Error != nil && m.PostSave != nil {
+	if m.PostSave != nil {
 		err := m.PostSave(model)
 		if err != nil {
 			return nil, err
 		}
 	}
+	return model, nil
+}
+
+// Save inserts or updates model. Version of versioned models is checked
+// and bumped, ErrStaleObject is returned if model was changed meanwhile.
+func (m SaveMethod[T]) Save(model *T) (*T, error) {
+	return m.withHooks(model, func() error {
+		return m.repo.saveVersioned(m.repo.dbConn, model)
+	})
+}
+
+// SaveReturning saves model and refreshes it with values stored by the
+// database, e.g. column defaults. MySQL selects model after save in a
+// transaction.
+func (m SaveMethod[T]) SaveReturning(model *T, options *ReturningOptions) (*T, error) {
+	return m.withHooks(model, func() error {
+		if supportsReturning(m.repo.dbConn) {
+			return m.repo.saveVersioned(m.repo.dbConn.Clauses(returningClause(options)), model)
+		}
 
-	return model, result.Error
+		return m.repo.dbConn.Transaction(func(tx *gorm.DB) error {
+			err := m.repo.saveVersioned(tx, model)
+			if err != nil {
+				return err
+			}
+			key, err := modelKey(tx, m.repo.IdField, model)
+			if err != nil {
+				return err
+			}
+			query := ApplyOptionOnly(tx.Model(model), returningOnly(options))
+			return query.Where(map[string]interface{}{m.repo.IdField: key}).Take(model).Error
+		})
+	})
 }
@@ -66,4 +66,38 @@ func TestSaveMethod(t *testing.T) {
 			t.Errorf("there were unfulfilled expectations: %s", err)
 		}
 	})
+
+	t.Run("Run PostSave after successful save only", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		saved := []string{}
+		repo.PostSave = func(model *MyModel) error {
+			saved = append(saved, model.Value)
+			return nil
+		}
+
+		sql := "INSERT INTO my_models (id,value,cnt) VALUES ($1,$2,$3)"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WillReturnError(fmt.Errorf("insert failed"))
+		mock.ExpectRollback()
+
+		_, err := repo.Save(&MyModel{Value: "first"})
+		assert.Nil(t, err)
+		_, err = repo.Save(&MyModel{Value: "second"})
+		assert.EqualError(t, err, "insert failed")
+		assert.Equal(t, []string{"first"}, saved)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
 }
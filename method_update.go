@@ -1,31 +1,177 @@
 package repository
 
 import (
-	"github.com/edkirin/gormfilterrepo/smartfilter"
-
+	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
 
 type UpdateMethod[T schema.Tabler] struct {
 	repo *RepoBase[T]
+	// PreUpdate and PostUpdate are called with column values of every
+	// update, PreUpdate may change them
+	PreUpdate  func(values map[string]any) error
+	PostUpdate func(values map[string]any) error
 }
 
 func (m *UpdateMethod[T]) Init(repo *RepoBase[T]) {
 	m.repo = repo
 }
 
+// withHooks runs update between update hooks
+func (m UpdateMethod[T]) withHooks(values map[string]any, update func() error) error {
+	if m.PreUpdate != nil {
+		err := m.PreUpdate(values)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := update()
+	if err != nil {
+		return err
+	}
+
+	if m.PostUpdate != nil {
+		return m.PostUpdate(values)
+	}
+	return nil
+}
+
+// expressions converts update expressions of values, see updateValues
+func (m UpdateMethod[T]) expressions(values map[string]any) (map[string]any, error) {
+	var model T
+	modelSchema, err := parseSchema(m.repo.dbConn, &model)
+	if err != nil {
+		return nil, err
+	}
+	return updateValues(modelSchema, values)
+}
+
+// Update updates models matching filter. Version of versioned models is
+// bumped, if version is given by values it is checked and ErrStaleObject
+// is returned if no model has it.
 func (m UpdateMethod[T]) Update(filter interface{}, values map[string]any) (int64, error) {
+	return m.update(filter, values, false)
+}
+
+// UpdateAll updates all models, filter is not required
+func (m UpdateMethod[T]) UpdateAll(values map[string]any) (int64, error) {
+	return m.update(nil, values, true)
+}
+
+func (m UpdateMethod[T]) update(filter interface{}, values map[string]any, all bool) (int64, error) {
+	var (
+		model        T
+		rowsAffected int64
+	)
+
+	err := m.withHooks(values, func() error {
+		values, version, err := m.repo.versioned(values)
+		if err != nil {
+			return err
+		}
+		values, err = m.expressions(values)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = m.repo.limitRowsAffected(func(tx *gorm.DB) (int64, error) {
+			query, err := mutationQuery[T](tx, filter, all)
+			if err != nil {
+				return 0, err
+			}
+			result := withCondition(query, version).Model(&model).Updates(values)
+			if result.Error == nil && version != nil && result.RowsAffected == 0 && !result.DryRun {
+				return 0, ErrStaleObject
+			}
+			return result.RowsAffected, result.Error
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
+}
+
+// UpdateReturning updates models matching filter and returns them as
+// updated. MySQL updates models selected for update in a transaction.
+func (m UpdateMethod[T]) UpdateReturning(filter interface{}, values map[string]any, options *ReturningOptions) (*[]T, error) {
 	var (
-		model T
+		model  T
+		models = []T{}
 	)
 
-	query, err := smartfilter.ToQuery(model, filter, m.repo.dbConn)
+	err := m.withHooks(values, func() error {
+		values, version, err := m.repo.versioned(values)
+		if err != nil {
+			return err
+		}
+		values, err = m.expressions(values)
+		if err != nil {
+			return err
+		}
+		_, err = m.repo.limitRowsAffected(func(tx *gorm.DB) (int64, error) {
+			if supportsReturning(tx) {
+				query, err := mutationQuery[T](tx, filter, false)
+				if err != nil {
+					return 0, err
+				}
+				result := withCondition(query, version).Model(&models).Clauses(returningClause(options)).Updates(values)
+				if result.Error == nil && version != nil && len(models) == 0 && !result.DryRun {
+					return 0, ErrStaleObject
+				}
+				return int64(len(models)), result.Error
+			}
+
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				keys, err := lockedKeys[T](withCondition(tx, version), m.repo.IdField, filter)
+				if err == nil && version != nil && len(keys) == 0 {
+					return ErrStaleObject
+				}
+				if err != nil || len(keys) == 0 {
+					return err
+				}
+				err = tx.Model(&model).Where(map[string]interface{}{m.repo.IdField: keys}).Updates(values).Error
+				if err != nil {
+					return err
+				}
+				models, err = selectByKeys[T](tx, m.repo.IdField, keys, options)
+				return err
+			})
+			return int64(len(models)), err
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models, nil
+}
+
+// UpdatePatch updates columns set in patch struct, see patchValues
+func (m UpdateMethod[T]) UpdatePatch(filter interface{}, patch interface{}) (int64, error) {
+	var model T
+	modelSchema, err := parseSchema(m.repo.dbConn, &model)
 	if err != nil {
 		return 0, err
 	}
-	result := query.Model(&model).Updates(values)
-	if result.Error != nil {
-		return 0, result.Error
+	values, err := patchValues(modelSchema, patch)
+	if err != nil {
+		return 0, err
+	}
+	return m.Update(filter, values)
+}
+
+// UpdateFields updates columns listed in fields to values of model.
+// Fields can be given by column or struct field names.
+func (m UpdateMethod[T]) UpdateFields(filter interface{}, model *T, fields []string) (int64, error) {
+	modelSchema, err := parseSchema(m.repo.dbConn, model)
+	if err != nil {
+		return 0, err
+	}
+	values, err := fieldMaskValues(modelSchema, model, fields)
+	if err != nil {
+		return 0, err
 	}
-	return result.RowsAffected, nil
+	return m.Update(filter, values)
 }
@@ -41,3 +41,125 @@ func TestUpdateMethod(t *testing.T) {
 		}
 	})
 }
+
+type Article struct {
+	Id    uint
+	Title string `gorm:"not null"`
+	Note  *string
+	Views int `gorm:"<-:create"`
+}
+
+func (m Article) TableName() string {
+	return "articles"
+}
+
+type ArticleFilter struct {
+	Id *uint `filterfield:"field=id;operator=EQ"`
+}
+
+type ArticlePatch struct {
+	Title  *string
+	Note   **string `patch:"note"`
+	Cached *bool    `patch:"-"`
+}
+
+func TestUpdatePatch(t *testing.T) {
+	sqldb, db, _ := NewMockDB()
+	defer sqldb.Close()
+
+	repo := RepoBase[Article]{}
+	repo.Init(db, nil)
+	id := uint(1)
+	filter := ArticleFilter{Id: &id}
+
+	t.Run("Update patch fields", func(t *testing.T) {
+		title := "new title"
+		note := "note"
+		notePtr := &note
+
+		statement, err := repo.ToSQL().UpdatePatch(filter, ArticlePatch{Title: &title, Note: &notePtr})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE articles SET note=$1,title=$2 WHERE articles.id = $3", statement.SQL)
+		assert.Equal(t, []interface{}{note, title, uint64(id)}, statement.Vars)
+
+		// nil inner pointer sets NULL, nil fields are unchanged
+		var null *string
+		statement, err = repo.ToSQL().UpdatePatch(filter, &ArticlePatch{Note: &null})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE articles SET note=$1 WHERE articles.id = $2", statement.SQL)
+		assert.Equal(t, []interface{}{nil, uint64(id)}, statement.Vars)
+	})
+
+	t.Run("Update masked fields", func(t *testing.T) {
+		statement, err := repo.ToSQL().UpdateFields(filter, &Article{Title: "title", Views: 5}, []string{"Title", "note"})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE articles SET note=$1,title=$2 WHERE articles.id = $3", statement.SQL)
+		assert.Equal(t, []interface{}{(*string)(nil), "title", uint64(id)}, statement.Vars)
+	})
+
+	t.Run("Run update hooks", func(t *testing.T) {
+		hooked := RepoBase[Article]{}
+		hooked.Init(db, nil)
+		calls := []string{}
+		hooked.PreUpdate = func(values map[string]any) error {
+			calls = append(calls, "pre")
+			values["title"] = "changed"
+			return nil
+		}
+		hooked.PostUpdate = func(values map[string]any) error {
+			calls = append(calls, "post")
+			return nil
+		}
+
+		title := "title"
+		statement, err := hooked.ToSQL().WithHooks().UpdatePatch(filter, ArticlePatch{Title: &title})
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{"changed", uint64(id)}, statement.Vars)
+		assert.Equal(t, []string{"pre", "post"}, calls)
+	})
+
+	t.Run("Fail on invalid patch", func(t *testing.T) {
+		type unknownColumn struct{ Body *string }
+		type notPointer struct{ Title string }
+		type invalidType struct{ Title *int }
+		type notUpdatable struct{ Views *int }
+		type nullTitle struct{ Title **string }
+
+		var null *string
+		for _, c := range []struct {
+			patch interface{}
+			err   string
+		}{
+			{unknownColumn{}, "unknownColumn.Body: unknown column: Body"},
+			{notPointer{}, "notPointer.Title: patch field must be a pointer"},
+			{invalidType{}, "invalidType.Title: invalid type for column title: int, expected string"},
+			{notUpdatable{}, "notUpdatable.Views: column can't be updated: Views"},
+			{nullTitle{Title: &null}, "nullTitle.Title: column can't be null: title"},
+			{"title", "patch must be a struct or pointer to struct, got string"},
+		} {
+			_, err := repo.UpdatePatch(filter, c.patch)
+			assert.EqualError(t, err, c.err)
+		}
+
+		_, err := repo.UpdateFields(filter, &Article{}, []string{"body"})
+		assert.EqualError(t, err, "unknown column: body")
+	})
+
+	t.Run("Memory repository", func(t *testing.T) {
+		memoryRepo := MemoryRepo[Article]{}
+		memoryRepo.Init(nil)
+		note := "note"
+		_, err := memoryRepo.Save(&Article{Title: "first", Note: &note})
+		assert.Nil(t, err)
+
+		var null *string
+		title := "patched"
+		updated, err := memoryRepo.UpdatePatch(filter, ArticlePatch{Title: &title, Note: &null})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		article, err := memoryRepo.Get(nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, Article{Id: 1, Title: "patched"}, *article)
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edkirin/gormfilterrepo/smartfilter"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrEmptyFilter is returned by Update and Delete methods when filter has
+// no conditions, UpdateAll and DeleteAll must be used to mutate all models
+var ErrEmptyFilter = errors.New("filter has no conditions")
+
+// ErrTooManyRowsAffected is returned when update or delete affects more
+// rows than MaxRowsAffected, changes are rolled back
+var ErrTooManyRowsAffected = errors.New("too many rows affected")
+
+// checkFilter rejects filters without conditions
+func checkFilter(filter interface{}) error {
+	empty, err := smartfilter.IsEmpty(filter)
+	if err != nil {
+		return err
+	}
+	if empty {
+		return ErrEmptyFilter
+	}
+	return nil
+}
+
+// mutationQuery applies filter of update or delete to query. Filter
+// without conditions is rejected unless all models are mutated.
+func mutationQuery[T schema.Tabler](query *gorm.DB, filter interface{}, all bool) (*gorm.DB, error) {
+	var model T
+	if all {
+		return query.Session(&gorm.Session{AllowGlobalUpdate: true}), nil
+	}
+	if err := checkFilter(filter); err != nil {
+		return nil, err
+	}
+	return smartfilter.ToQuery(model, filter, query)
+}
+
+// checkRowsAffected returns ErrTooManyRowsAffected if maxRowsAffected is
+// set and exceeded
+func checkRowsAffected(rowsAffected int64, maxRowsAffected int64) error {
+	if maxRowsAffected > 0 && rowsAffected > maxRowsAffected {
+		return fmt.Errorf("%w: %d, max %d", ErrTooManyRowsAffected, rowsAffected, maxRowsAffected)
+	}
+	return nil
+}
+
+// limitRowsAffected runs mutation in a transaction, which is rolled back if
+// more than MaxRowsAffected rows are affected. Mutation is run without
+// transaction if MaxRowsAffected isn't set.
+func (m *RepoBase[T]) limitRowsAffected(mutate func(tx *gorm.DB) (int64, error)) (int64, error) {
+	if m.MaxRowsAffected <= 0 {
+		return mutate(m.dbConn)
+	}
+
+	var rowsAffected int64
+	err := m.dbConn.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rowsAffected, err = mutate(tx)
+		if err != nil {
+			return err
+		}
+		return checkRowsAffected(rowsAffected, m.MaxRowsAffected)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutationGuards(t *testing.T) {
+	t.Run("Empty filters are rejected", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		for _, filter := range []interface{}{nil, MyModelFilter{}, &MyModelFilter{}} {
+			_, err := repo.Update(filter, map[string]any{"cnt": 1})
+			assert.ErrorIs(t, err, ErrEmptyFilter)
+			_, err = repo.UpdateReturning(filter, map[string]any{"cnt": 1}, nil)
+			assert.ErrorIs(t, err, ErrEmptyFilter)
+			_, err = repo.Delete(filter)
+			assert.ErrorIs(t, err, ErrEmptyFilter)
+			_, err = repo.DeleteReturning(filter, nil)
+			assert.ErrorIs(t, err, ErrEmptyFilter)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Mutate all models", func(t *testing.T) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		statement, err := repo.ToSQL().UpdateAll(map[string]any{"cnt": 1})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE my_models SET cnt=$1", statement.SQL)
+
+		statement, err = repo.ToSQL().DeleteAll()
+		assert.Nil(t, err)
+		assert.Equal(t, "DELETE FROM my_models", statement.SQL)
+	})
+
+	t.Run("Too many rows affected", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, &RepoOptions{MaxRowsAffected: 2})
+		assert.Equal(t, DEFAULT_ID_FIELD, repo.IdField)
+
+		cnt := 10
+		sql := "UPDATE my_models SET cnt=$1 WHERE my_models.cnt > $2"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(1, cnt).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectRollback()
+
+		_, err := repo.Update(MyModelFilter{CntGT: &cnt}, map[string]any{"cnt": 1})
+		assert.ErrorIs(t, err, ErrTooManyRowsAffected)
+		assert.EqualError(t, err, "too many rows affected: 3, max 2")
+
+		sql = "DELETE FROM my_models"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		deleted, err := repo.DeleteAll()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Memory repository", func(t *testing.T) {
+		repo, models := newMemoryRepo(t)
+		repo.MaxRowsAffected = 2
+
+		_, err := repo.Delete(MyModelFilter{})
+		assert.ErrorIs(t, err, ErrEmptyFilter)
+		_, err = repo.UpdateAll(map[string]any{"cnt": 0})
+		assert.ErrorIs(t, err, ErrTooManyRowsAffected)
+
+		result, err := repo.List(nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models, *result)
+
+		cnt := 1
+		deleted, err := repo.Delete(MyModelFilter{CntGT: &cnt})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), deleted)
+		deleted, err = repo.DeleteAll()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"fmt"
+	"regexp"
 
+	"github.com/edkirin/gormfilterrepo/smartfilter"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Pagination struct {
@@ -18,9 +21,39 @@ const (
 	OrderDESC OrderDirection = "DESC"
 )
 
+type OrderNulls string
+
+const (
+	OrderNullsFirst OrderNulls = "FIRST"
+	OrderNullsLast  OrderNulls = "LAST"
+)
+
+// collation names are quoted by dialector, but still embedded into SQL
+var collationRegexp = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
 type Order struct {
 	Field     string
 	Direction OrderDirection
+	// Table qualifies field, e.g. with joined table name
+	Table string
+	// Nulls places NULL values first or last. MySQL has no NULLS FIRST/LAST,
+	// it is emulated by ordering by "field IS NULL" first.
+	Nulls OrderNulls
+	// Collate orders by field using given collation
+	Collate string
+	// Expression orders by SQL expression instead of field, e.g. "LOWER(name)".
+	// Values are passed as Vars and bound to "?" placeholders, expression
+	// itself must never contain user input.
+	Expression string
+	Vars       []interface{}
+}
+
+// withCondition adds condition to query, nil condition is skipped
+func withCondition(query *gorm.DB, condition clause.Expression) *gorm.DB {
+	if condition == nil {
+		return query
+	}
+	return query.Where(condition)
 }
 
 func ApplyJoins(query *gorm.DB, joins []string) *gorm.DB {
@@ -41,17 +74,79 @@ func ApplyOptionOnly(query *gorm.DB, only []string) *gorm.DB {
 	return query
 }
 
+// orderExpression builds ordering expression of a single order
+func orderExpression(query *gorm.DB, order Order) (clause.Expr, error) {
+	var (
+		target string
+		vars   []interface{}
+	)
+	dialect := query.Dialector.Name()
+
+	if len(order.Expression) > 0 {
+		if len(order.Field) > 0 || len(order.Table) > 0 {
+			return clause.Expr{}, fmt.Errorf("order expression can't be combined with field")
+		}
+		target = order.Expression
+		vars = order.Vars
+	} else {
+		// field and table are embedded into SQL
+		if !smartfilter.IsIdentifier(order.Field) {
+			return clause.Expr{}, fmt.Errorf("invalid order field: %s", order.Field)
+		}
+		if len(order.Table) > 0 && !smartfilter.IsIdentifier(order.Table) {
+			return clause.Expr{}, fmt.Errorf("invalid order table: %s", order.Table)
+		}
+		target = query.Statement.Quote(clause.Column{Table: order.Table, Name: order.Field})
+	}
+
+	sql := target
+	if len(order.Collate) > 0 {
+		if !collationRegexp.MatchString(order.Collate) {
+			return clause.Expr{}, fmt.Errorf("invalid order collation: %s", order.Collate)
+		}
+		sql = fmt.Sprintf("%s COLLATE %s", sql, query.Statement.Quote(order.Collate))
+	}
+
+	switch order.Direction {
+	case "", OrderASC:
+	case OrderDESC:
+		sql = fmt.Sprintf("%s %s", sql, order.Direction)
+	default:
+		return clause.Expr{}, fmt.Errorf("invalid order direction: %s", order.Direction)
+	}
+
+	switch order.Nulls {
+	case "":
+	case OrderNullsFirst, OrderNullsLast:
+		if dialect != "mysql" {
+			sql = fmt.Sprintf("%s NULLS %s", sql, order.Nulls)
+			break
+		}
+		// false sorts before true
+		nullsDirection := ""
+		if order.Nulls == OrderNullsFirst {
+			nullsDirection = " DESC"
+		}
+		sql = fmt.Sprintf("%s IS NULL%s,%s", target, nullsDirection, sql)
+		vars = append(append([]interface{}{}, vars...), vars...)
+	default:
+		return clause.Expr{}, fmt.Errorf("invalid order nulls: %s", order.Nulls)
+	}
+
+	return clause.Expr{SQL: sql, Vars: vars, WithoutParentheses: true}, nil
+}
+
 func ApplyOptionOrdering(query *gorm.DB, ordering []Order) *gorm.DB {
 	if len(ordering) == 0 {
 		return query
 	}
 
 	for _, order := range ordering {
-		if len(order.Direction) == 0 || order.Direction == OrderASC {
-			query = query.Order(fmt.Sprintf(`"%s"`, order.Field))
-		} else {
-			query = query.Order(fmt.Sprintf(`"%s" %s`, order.Field, order.Direction))
+		expression, err := orderExpression(query, order)
+		if err != nil {
+			return smartfilter.WithError(query, err)
 		}
+		query = smartfilter.AppendOrder(query, expression)
 	}
 	return query
 }
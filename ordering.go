@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/edkirin/gormfilterrepo/smartfilter"
+	"gorm.io/gorm/schema"
+)
+
+const ORDERING_SEPARATOR = smartfilter.ORDERING_SEPARATOR
+const ORDERING_DIRECTION_SEPARATOR = smartfilter.ORDERING_DIRECTION_SEPARATOR
+
+// OrderingError describes invalid item of ordering string
+type OrderingError = smartfilter.OrderingError
+
+// OrderingErrors holds all errors found while parsing ordering string
+type OrderingErrors = smartfilter.OrderingErrors
+
+// ParseOrdering parses user provided ordering string, e.g.
+// "-created_at,name". Fields are ordered ascending, unless prefixed with
+// "-", direction may also be given as suffix, e.g. "name:desc". Only fields
+// present in allowlist may be used, they are translated to allowlisted
+// columns. Invalid items are reported as OrderingErrors.
+func ParseOrdering(ordering string, allowlist smartfilter.FieldAllowlist) ([]Order, error) {
+	columns, err := smartfilter.ParseOrdering(ordering, allowlist)
+	if err != nil {
+		return nil, err
+	}
+	return orderingOf(columns), nil
+}
+
+// ParseModelOrdering parses user provided ordering string, allowing all
+// columns of model T
+func ParseModelOrdering[T schema.Tabler](ordering string) ([]Order, error) {
+	var model T
+	allowlist, err := smartfilter.ModelAllowlist(model)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOrdering(ordering, allowlist)
+}
+
+func orderingOf(columns []smartfilter.OrderColumn) []Order {
+	orders := make([]Order, 0, len(columns))
+	for _, column := range columns {
+		direction := OrderASC
+		if column.Desc {
+			direction = OrderDESC
+		}
+		orders = append(orders, Order{Table: column.Table, Field: column.Field, Direction: direction})
+	}
+	return orders
+}
+
+// resolveOrdering returns ordering of operation. Ordering given by options
+// takes precedence over ordering field of filter, repository default
+// ordering is used if neither is set.
+func resolveOrdering(filter interface{}, ordering []Order, defaultOrdering []Order) ([]Order, error) {
+	if len(ordering) > 0 {
+		return ordering, nil
+	}
+	columns, err := smartfilter.FilterOrdering(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) > 0 {
+		return orderingOf(columns), nil
+	}
+	return defaultOrdering, nil
+}
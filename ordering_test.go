@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/edkirin/gormfilterrepo/smartfilter"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dialectOverride reports different dialect name, allowing dialect specific
+// SQL to be checked using postgres mock connection
+type dialectOverride struct {
+	gorm.Dialector
+	name string
+}
+
+func (d dialectOverride) Name() string {
+	return d.name
+}
+
+// QuoteTo quotes identifiers with backticks on mysql, mock connection
+// doesn't quote them at all
+func (d dialectOverride) QuoteTo(writer clause.Writer, str string) {
+	if d.name != "mysql" {
+		d.Dialector.QuoteTo(writer, str)
+		return
+	}
+	parts := strings.Split(str, ".")
+	for idx, part := range parts {
+		if idx > 0 {
+			writer.WriteByte('.')
+		}
+		writer.WriteByte('`')
+		writer.WriteString(part)
+		writer.WriteByte('`')
+	}
+}
+
+func TestParseOrdering(t *testing.T) {
+	allowlist := smartfilter.FieldAllowlist{
+		"created": "created_at",
+		"name":    "name",
+		"color":   "attrs->>color",
+	}
+
+	t.Run("Parse ordering", func(t *testing.T) {
+		ordering, err := ParseOrdering(" -created, name ,", allowlist)
+		assert.Nil(t, err)
+		assert.Equal(t, []Order{
+			{Field: "created_at", Direction: OrderDESC},
+			{Field: "name", Direction: OrderASC},
+		}, ordering)
+
+		ordering, err = ParseOrdering("name:DESC,+created", allowlist)
+		assert.Nil(t, err)
+		assert.Equal(t, []Order{
+			{Field: "name", Direction: OrderDESC},
+			{Field: "created_at", Direction: OrderASC},
+		}, ordering)
+
+		ordering, err = ParseOrdering("customer", smartfilter.FieldAllowlist{"customer": "customers.name"})
+		assert.Nil(t, err)
+		assert.Equal(t, []Order{{Table: "customers", Field: "name", Direction: OrderASC}}, ordering)
+
+		ordering, err = ParseOrdering("", allowlist)
+		assert.Nil(t, err)
+		assert.Equal(t, []Order{}, ordering)
+	})
+
+	t.Run("Report all errors", func(t *testing.T) {
+		_, err := ParseOrdering(`name:up,-password,name,-name:asc,color,-,created;drop table`, allowlist)
+		assert.Equal(t, OrderingErrors{
+			{Value: "name:up", Message: "invalid direction: up"},
+			{Value: "-password", Message: "field not allowed: password"},
+			{Value: "-name:asc", Message: "direction given both as prefix and suffix"},
+			{Value: "color", Message: "column can't be used for ordering: attrs->>color"},
+			{Value: "-", Message: "missing field name"},
+			{Value: "created;drop table", Message: "field not allowed: created;drop table"},
+		}, err)
+		assert.EqualError(t, err, "name:up: invalid direction: up; -password: field not allowed: password; -name:asc: direction given both as prefix and suffix; color: column can't be used for ordering: attrs->>color; -: missing field name; created;drop table: field not allowed: created;drop table")
+
+		_, err = ParseOrdering("name,-name", allowlist)
+		assert.Equal(t, OrderingErrors{{Value: "-name", Message: "duplicate ordering field"}}, err)
+	})
+
+	t.Run("Allow model columns", func(t *testing.T) {
+		ordering, err := ParseModelOrdering[MyModel]("-cnt,value")
+		assert.Nil(t, err)
+		assert.Equal(t, []Order{
+			{Field: "cnt", Direction: OrderDESC},
+			{Field: "value", Direction: OrderASC},
+		}, ordering)
+
+		_, err = ParseModelOrdering[MyModel]("Cnt")
+		assert.EqualError(t, err, "Cnt: field not allowed: Cnt")
+	})
+}
+
+func TestApplyOptionOrderingValidation(t *testing.T) {
+	sqldb, db, mock := NewMockDB()
+	defer sqldb.Close()
+
+	repo := RepoBase[MyModel]{}
+	repo.Init(db, nil)
+
+	_, err := repo.List(MyModelFilter{}, &ListOptions{
+		Ordering: []Order{{Field: `id"; DROP TABLE my_models; --`}},
+	})
+	assert.EqualError(t, err, `invalid order field: id"; DROP TABLE my_models; --`)
+
+	_, err = repo.Get(MyModelFilter{}, &GetOptions{
+		Ordering: []Order{{Field: "id", Direction: "DESC; DROP TABLE my_models"}},
+	})
+	assert.EqualError(t, err, "invalid order direction: DESC; DROP TABLE my_models")
+
+	// errors are not kept on the shared connection
+	sql := `SELECT * FROM my_models ORDER BY id DESC`
+	mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql)))
+
+	_, err = repo.List(MyModelFilter{}, &ListOptions{
+		Ordering: []Order{{Field: "id", Direction: OrderDESC}},
+	})
+	assert.Nil(t, err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRichOrdering(t *testing.T) {
+	listSQL := func(t *testing.T, dialect string, ordering []Order) (string, []interface{}, error) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+		if len(dialect) > 0 {
+			db.Dialector = dialectOverride{Dialector: db.Dialector, name: dialect}
+		}
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		statement, err := repo.ToSQL().List(MyModelFilter{}, &ListOptions{Ordering: ordering})
+		if err != nil {
+			return "", nil, err
+		}
+		return statement.SQL, statement.Vars, nil
+	}
+
+	t.Run("Table, collation and nulls", func(t *testing.T) {
+		sql, _, err := listSQL(t, "", []Order{
+			{Table: "customers", Field: "name", Collate: "en-US-x-icu", Direction: OrderDESC, Nulls: OrderNullsLast},
+			{Field: "id", Nulls: OrderNullsFirst},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY customers.name COLLATE en-US-x-icu DESC NULLS LAST,id NULLS FIRST`, sql)
+	})
+
+	t.Run("Parameterised expression", func(t *testing.T) {
+		sql, vars, err := listSQL(t, "", []Order{
+			{Field: "cnt"},
+			{Expression: "value = ?", Vars: []interface{}{"pinned"}, Direction: OrderDESC},
+			{Expression: "LOWER(value)"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY cnt,value = $1 DESC,LOWER(value)`, sql)
+		assert.Equal(t, []interface{}{"pinned"}, vars)
+	})
+
+	t.Run("Get keeps parameterised expression", func(t *testing.T) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		statement, err := repo.ToSQL().Get(MyModelFilter{}, &GetOptions{
+			Ordering: []Order{{Expression: "value = ?", Vars: []interface{}{"pinned"}, Direction: OrderDESC}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY value = $1 DESC,my_models.id LIMIT $2`, statement.SQL)
+		assert.Equal(t, []interface{}{"pinned", 1}, statement.Vars)
+	})
+
+	t.Run("Emulate nulls ordering on mysql", func(t *testing.T) {
+		sql, vars, err := listSQL(t, "mysql", []Order{
+			{Field: "value", Nulls: OrderNullsFirst, Collate: "utf8mb4_bin"},
+			{Expression: "COALESCE(cnt, ?)", Vars: []interface{}{0}, Direction: OrderDESC, Nulls: OrderNullsLast},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM `my_models` ORDER BY `value` IS NULL DESC,`value` COLLATE `utf8mb4_bin`,COALESCE(cnt, $1) IS NULL,COALESCE(cnt, $2) DESC", sql)
+		assert.Equal(t, []interface{}{0, 0}, vars)
+	})
+
+	t.Run("Fail on invalid options", func(t *testing.T) {
+		for _, c := range []struct {
+			order Order
+			err   string
+		}{
+			{Order{Table: "customers; --", Field: "name"}, "invalid order table: customers; --"},
+			{Order{Field: "name", Collate: `C"; --`}, `invalid order collation: C"; --`},
+			{Order{Field: "name", Nulls: "MIDDLE"}, "invalid order nulls: MIDDLE"},
+			{Order{Field: "name", Expression: "LOWER(name)"}, "order expression can't be combined with field"},
+		} {
+			_, _, err := listSQL(t, "", []Order{c.order})
+			assert.EqualError(t, err, c.err)
+		}
+	})
+}
+
+type orderedModelFilter struct {
+	Value *string `filterfield:"field=value;operator=EQ"`
+	Order *string `filterorder:"value,count=cnt"`
+}
+
+func TestDefaultOrdering(t *testing.T) {
+	sqldb, db, _ := NewMockDB()
+	defer sqldb.Close()
+
+	repo := RepoBase[MyModel]{}
+	repo.Init(db, &RepoOptions{
+		DefaultOrdering: []Order{{Field: "value", Direction: OrderDESC}},
+	})
+	order := "-count"
+
+	t.Run("Default ordering", func(t *testing.T) {
+		statement, err := repo.ToSQL().List(orderedModelFilter{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY value DESC`, statement.SQL)
+
+		statement, err = repo.ToSQL().Get(MyModelFilter{}, &GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY value DESC,my_models.id LIMIT $1`, statement.SQL)
+	})
+
+	t.Run("Filter ordering replaces default", func(t *testing.T) {
+		statement, err := repo.ToSQL().List(orderedModelFilter{Order: &order}, &ListOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY cnt DESC`, statement.SQL)
+
+		statement, err = repo.ToSQL().Get(&orderedModelFilter{Order: &order}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY cnt DESC,my_models.id LIMIT $1`, statement.SQL)
+	})
+
+	t.Run("Options ordering replaces filter ordering", func(t *testing.T) {
+		statement, err := repo.ToSQL().List(orderedModelFilter{Order: &order}, &ListOptions{
+			Ordering: []Order{{Field: "id"}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models ORDER BY id`, statement.SQL)
+	})
+
+	t.Run("Fail on invalid filter ordering", func(t *testing.T) {
+		invalid := "id"
+		_, err := repo.List(orderedModelFilter{Order: &invalid}, nil)
+		assert.Equal(t, OrderingErrors{{Value: "id", Message: "field not allowed: id"}}, err)
+	})
+
+	t.Run("Memory repository", func(t *testing.T) {
+		memoryValues := func(models []MyModel) []string {
+			values := []string{}
+			for _, model := range models {
+				values = append(values, model.Value)
+			}
+			return values
+		}
+
+		repo, _ := newMemoryRepo(t)
+		repo.DefaultOrdering = []Order{{Field: "value", Direction: OrderDESC}}
+
+		models, err := repo.List(orderedModelFilter{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"third", "second", "first"}, memoryValues(*models))
+
+		models, err = repo.List(orderedModelFilter{Order: &order}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"first", "third", "second"}, memoryValues(*models))
+
+		model, err := repo.Get(orderedModelFilter{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "third", model.Value)
+	})
+}
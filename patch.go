@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+const PATCH_TAG_NAME = "patch"
+
+// updateField returns model field of column, which must be updatable.
+// Column can be given by column or struct field name.
+func updateField(modelSchema *schema.Schema, column string) (*schema.Field, error) {
+	field := modelSchema.LookUpField(column)
+	if field == nil || len(field.DBName) == 0 {
+		return nil, fmt.Errorf("unknown column: %s", column)
+	}
+	if !field.Updatable {
+		return nil, fmt.Errorf("column can't be updated: %s", column)
+	}
+	return field, nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// patchValues converts patch struct to column values. Patch fields must be
+// pointers, nil fields are left unchanged. Fields are matched to model
+// fields of the same name, unless column is given by "patch" tag, "-"
+// skips the field. Double pointer fields set NULL if the inner pointer is
+// nil.
+//
+//	type MyModelPatch struct {
+//		Value *string
+//		Note  **string `patch:"note"`
+//	}
+func patchValues(modelSchema *schema.Schema, patch interface{}) (map[string]interface{}, error) {
+	patchValue, err := indirectPatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	patchType := patchValue.Type()
+
+	values := map[string]interface{}{}
+	for i := 0; i < patchType.NumField(); i++ {
+		structField := patchType.Field(i)
+		column := structField.Tag.Get(PATCH_TAG_NAME)
+		if column == "-" || !structField.IsExported() {
+			continue
+		}
+		if len(column) == 0 {
+			column = structField.Name
+		}
+
+		if structField.Type.Kind() != reflect.Pointer {
+			return nil, fmt.Errorf("%s.%s: patch field must be a pointer", patchType.Name(), structField.Name)
+		}
+		field, err := updateField(modelSchema, column)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %s", patchType.Name(), structField.Name, err)
+		}
+		valueType := indirectType(structField.Type)
+		if !valueType.AssignableTo(indirectType(field.FieldType)) {
+			return nil, fmt.Errorf(
+				"%s.%s: invalid type for column %s: %v, expected %v",
+				patchType.Name(), structField.Name, field.DBName, valueType, indirectType(field.FieldType),
+			)
+		}
+
+		value := patchValue.Field(i)
+		if value.IsNil() {
+			continue
+		}
+		value = value.Elem()
+		if value.Kind() == reflect.Pointer && value.IsNil() {
+			if field.NotNull {
+				return nil, fmt.Errorf("%s.%s: column can't be null: %s", patchType.Name(), structField.Name, field.DBName)
+			}
+			values[field.DBName] = nil
+			continue
+		}
+		values[field.DBName] = reflect.Indirect(value).Interface()
+	}
+	return values, nil
+}
+
+func indirectPatch(patch interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(patch)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("patch must be a struct or pointer to struct, got %T", patch)
+	}
+	return value, nil
+}
+
+// fieldMaskValues returns column values of model fields listed in mask,
+// zero values and nil pointers are included
+func fieldMaskValues[T schema.Tabler](modelSchema *schema.Schema, model *T, mask []string) (map[string]interface{}, error) {
+	if model == nil {
+		return nil, fmt.Errorf("model must not be nil")
+	}
+
+	values := map[string]interface{}{}
+	for _, column := range mask {
+		field, err := updateField(modelSchema, column)
+		if err != nil {
+			return nil, err
+		}
+		value, _ := field.ValueOf(context.Background(), reflect.ValueOf(model).Elem())
+		values[field.DBName] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edkirin/gormfilterrepo/smartfilter"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const ASSOCIATION_PATH_SEPARATOR = "."
+
+// Preload loads association of model in a separate query. Association is
+// a path of association field names, e.g. "Items.Product", associations
+// along the path are loaded as well.
+type Preload struct {
+	Association string
+	// Filter and Ordering apply to the last association of path
+	Filter   interface{}
+	Ordering []Order
+	// Limit is the number of associated models loaded per parent model, it
+	// is supported by has many associations only
+	Limit int
+}
+
+// tableName is used as model of associations, filters need only its
+// table name
+type tableName string
+
+func (t tableName) TableName() string {
+	return string(t)
+}
+
+// parseSchema parses schema of model using schema cache of connection
+func parseSchema(query *gorm.DB, model interface{}) (*schema.Schema, error) {
+	statement := &gorm.Statement{DB: query}
+	if err := statement.Parse(model); err != nil {
+		return nil, err
+	}
+	return statement.Schema, nil
+}
+
+// lookUpAssociation returns relationship of the last association of path
+func lookUpAssociation(modelSchema *schema.Schema, path string) (*schema.Relationship, error) {
+	var relationship *schema.Relationship
+	for _, name := range strings.Split(path, ASSOCIATION_PATH_SEPARATOR) {
+		relationship = modelSchema.Relationships.Relations[name]
+		if relationship == nil {
+			return nil, fmt.Errorf("unknown association: %s", path)
+		}
+		modelSchema = relationship.FieldSchema
+	}
+	return relationship, nil
+}
+
+// preloadConditions validates preload options and returns function
+// applying them to association query
+func preloadConditions(query *gorm.DB, relationship *schema.Relationship, preload Preload) (func(tx *gorm.DB) *gorm.DB, error) {
+	table := tableName(relationship.FieldSchema.Table)
+
+	// filter and ordering are checked upfront, preload queries are executed
+	// after the main one
+	_, err := smartfilter.ToQuery(table, preload.Filter, query.Session(&gorm.Session{NewDB: true}))
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range preload.Ordering {
+		if _, err := orderExpression(query, order); err != nil {
+			return nil, err
+		}
+	}
+
+	var partition []string
+	switch {
+	case preload.Limit < 0:
+		return nil, fmt.Errorf("invalid preload limit: %d", preload.Limit)
+	case preload.Limit > 0:
+		if relationship.Type != schema.HasMany {
+			return nil, fmt.Errorf("limit is supported only by has many associations")
+		}
+		if relationship.FieldSchema.PrioritizedPrimaryField == nil {
+			return nil, fmt.Errorf("limit requires association with primary key")
+		}
+		for _, reference := range relationship.References {
+			if reference.OwnPrimaryKey {
+				partition = append(partition, reference.ForeignKey.DBName)
+			}
+		}
+	}
+
+	return func(tx *gorm.DB) *gorm.DB {
+		var err error
+		tx, err = smartfilter.ToQuery(table, preload.Filter, tx)
+		if err != nil {
+			return smartfilter.WithError(tx, err)
+		}
+		tx = ApplyOptionOrdering(tx, preload.Ordering)
+		if preload.Limit == 0 {
+			return tx
+		}
+
+		// rows are numbered per parent model, only first Limit rows of each
+		// parent are loaded
+		primaryKeyName := relationship.FieldSchema.PrioritizedPrimaryField.DBName
+		primaryKey := tx.Statement.Quote(primaryKeyName)
+		partitionBy := make([]string, 0, len(partition))
+		for _, column := range partition {
+			partitionBy = append(partitionBy, tx.Statement.Quote(column))
+		}
+		ordering := make([]string, 0, len(preload.Ordering))
+		vars := make([]interface{}, 0, len(preload.Ordering))
+		for _, order := range preload.Ordering {
+			expression, _ := orderExpression(tx, order)
+			ordering = append(ordering, "?")
+			vars = append(vars, expression)
+		}
+		if len(ordering) == 0 {
+			ordering = append(ordering, primaryKey)
+		}
+
+		numbered := tx.Session(&gorm.Session{NewDB: true}).Table(string(table))
+		for _, reference := range relationship.References {
+			if !reference.OwnPrimaryKey && len(reference.PrimaryValue) > 0 {
+				numbered = numbered.Where(clause.Eq{Column: reference.ForeignKey.DBName, Value: reference.PrimaryValue})
+			}
+		}
+		numbered, err = smartfilter.ToQuery(table, preload.Filter, numbered)
+		if err != nil {
+			return smartfilter.WithError(tx, err)
+		}
+		numbered = numbered.Select(fmt.Sprintf(
+			"%s,ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS preload_row",
+			primaryKey, strings.Join(partitionBy, ","), strings.Join(ordering, ","),
+		), vars...)
+
+		limited := tx.Session(&gorm.Session{NewDB: true}).
+			Table("(?) AS preload_rows", numbered).
+			Select(primaryKey).
+			Where("preload_row <= ?", preload.Limit)
+		return tx.Where("? IN (?)", clause.Column{Table: string(table), Name: primaryKeyName}, limited)
+	}, nil
+}
+
+// ApplyPreload preloads associations of model, associations are validated
+// against model relationships
+func ApplyPreload(query *gorm.DB, model schema.Tabler, preloads []Preload) *gorm.DB {
+	if len(preloads) == 0 {
+		return query
+	}
+
+	modelSchema, err := parseSchema(query, model)
+	if err != nil {
+		return smartfilter.WithError(query, err)
+	}
+
+	for _, preload := range preloads {
+		relationship, err := lookUpAssociation(modelSchema, preload.Association)
+		if err != nil {
+			return smartfilter.WithError(query, err)
+		}
+		if preload.Filter == nil && len(preload.Ordering) == 0 && preload.Limit == 0 {
+			query = query.Preload(preload.Association)
+			continue
+		}
+
+		conditions, err := preloadConditions(query, relationship, preload)
+		if err != nil {
+			return smartfilter.WithError(query, fmt.Errorf("%s: %s", preload.Association, err))
+		}
+		query = query.Preload(preload.Association, conditions)
+	}
+	return query
+}
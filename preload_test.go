@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type Product struct {
+	Id   uint
+	Name string
+}
+
+func (m Product) TableName() string {
+	return "products"
+}
+
+type OrderItem struct {
+	Id        uint
+	OrderId   uint
+	ProductId uint
+	Product   *Product
+	Qty       int
+}
+
+func (m OrderItem) TableName() string {
+	return "order_items"
+}
+
+type PurchaseOrder struct {
+	Id         uint
+	CustomerId uint
+	Customer   *Customer
+	Items      []OrderItem `gorm:"foreignKey:OrderId"`
+}
+
+func (m PurchaseOrder) TableName() string {
+	return "orders"
+}
+
+type OrderItemFilter struct {
+	QtyGT *int `filterfield:"field=qty;operator=GT"`
+}
+
+func TestPreload(t *testing.T) {
+	t.Run("Nested associations", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[PurchaseOrder]{}
+		repo.Init(db, nil)
+
+		sql := "SELECT * FROM orders"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		sql = "SELECT * FROM order_items WHERE order_items.order_id IN ($1,$2)"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "product_id"}).AddRow(10, 1, 100).AddRow(11, 2, 100))
+		sql = "SELECT * FROM products WHERE products.id = $1"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).WithArgs(100).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(100, "pencil"))
+
+		result, err := repo.List(nil, &ListOptions{
+			Preload: []Preload{{Association: "Items.Product"}},
+		})
+		assert.Nil(t, err)
+		assert.Len(t, *result, 2)
+		assert.Equal(t, "pencil", (*result)[1].Items[0].Product.Name)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Filter, ordering and limit", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[PurchaseOrder]{}
+		repo.Init(db, nil)
+
+		qty := 1
+		sql := "SELECT * FROM orders ORDER BY orders.id LIMIT $1"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sql = `SELECT * FROM order_items WHERE order_items.qty > $1 AND order_items.id IN ` +
+			`(SELECT id FROM (SELECT id,ROW_NUMBER() OVER (PARTITION BY order_id ORDER BY qty DESC) AS preload_row ` +
+			`FROM order_items WHERE order_items.qty > $2) AS preload_rows WHERE preload_row <= $3) ` +
+			`AND order_items.order_id = $4 ORDER BY qty DESC`
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).WithArgs(qty, qty, 3, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "qty"}).AddRow(10, 1, 5).AddRow(11, 1, 2))
+
+		result, err := repo.Get(nil, &GetOptions{
+			Preload: []Preload{{
+				Association: "Items",
+				Filter:      OrderItemFilter{QtyGT: &qty},
+				Ordering:    []Order{{Field: "qty", Direction: OrderDESC}},
+				Limit:       3,
+			}},
+		})
+		assert.Nil(t, err)
+		if assert.NotNil(t, result) {
+			assert.Len(t, result.Items, 2)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Limit quotes identifiers through dialector", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+		db.Dialector = dialectOverride{Dialector: db.Dialector, name: "mysql"}
+
+		repo := RepoBase[PurchaseOrder]{}
+		repo.Init(db, nil)
+
+		sql := "SELECT * FROM `orders` ORDER BY `orders`.`id` LIMIT $1"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sql = "SELECT * FROM `order_items` WHERE `order_items`.`id` IN " +
+			"(SELECT `id` FROM (SELECT `id`,ROW_NUMBER() OVER (PARTITION BY `order_id` ORDER BY `id`) AS preload_row " +
+			"FROM `order_items`) AS preload_rows WHERE preload_row <= $1) AND `order_items`.`order_id` = $2"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "qty"}).AddRow(10, 1, 5))
+
+		_, err := repo.Get(nil, &GetOptions{
+			Preload: []Preload{{Association: "Items", Limit: 2}},
+		})
+		assert.Nil(t, err)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Fail on invalid preload", func(t *testing.T) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[PurchaseOrder]{}
+		repo.Init(db, nil)
+
+		for _, c := range []struct {
+			preload Preload
+			err     string
+		}{
+			{Preload{Association: "Items.Vendor"}, "unknown association: Items.Vendor"},
+			{Preload{Association: "Items.Product", Limit: 1}, "Items.Product: limit is supported only by has many associations"},
+			{Preload{Association: "Items", Limit: -1}, "Items: invalid preload limit: -1"},
+			{Preload{Association: "Items", Ordering: []Order{{Field: "qty; --"}}}, "Items: invalid order field: qty; --"},
+			{Preload{Association: "Items", Filter: "qty"}, "Items: filter must be a struct or pointer to struct, got string"},
+		} {
+			_, err := repo.List(nil, &ListOptions{Preload: []Preload{c.preload}})
+			assert.EqualError(t, err, c.err)
+		}
+
+		memoryRepo := MemoryRepo[PurchaseOrder]{}
+		memoryRepo.Init(nil)
+		_, err := memoryRepo.List(nil, &ListOptions{Preload: []Preload{{Association: "Items"}}})
+		assert.EqualError(t, err, "preload is not supported by memory repository")
+	})
+}
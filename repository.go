@@ -11,13 +11,51 @@ type MethodInitInterface[T schema.Tabler] interface {
 	Init(repo *RepoBase[T])
 }
 
+// Repository lists all repository operations. It is implemented by RepoBase
+// and MemoryRepo.
+type Repository[T schema.Tabler] interface {
+	List(filter interface{}, options *ListOptions) (*[]T, error)
+	Get(filter interface{}, options *GetOptions) (*T, error)
+	Exists(filter interface{}) (bool, error)
+	Count(filter interface{}) (int64, error)
+	Save(model *T) (*T, error)
+	SaveReturning(model *T, options *ReturningOptions) (*T, error)
+	Update(filter interface{}, values map[string]any) (int64, error)
+	UpdateReturning(filter interface{}, values map[string]any, options *ReturningOptions) (*[]T, error)
+	UpdatePatch(filter interface{}, patch interface{}) (int64, error)
+	UpdateFields(filter interface{}, model *T, fields []string) (int64, error)
+	UpdateAll(values map[string]any) (int64, error)
+	Delete(filter interface{}) (int64, error)
+	DeleteReturning(filter interface{}, options *ReturningOptions) (*[]T, error)
+	DeleteAll() (int64, error)
+}
+
+// make sure repositories implement all operations
+var (
+	_ Repository[schema.Tabler] = (*RepoBase[schema.Tabler])(nil)
+	_ Repository[schema.Tabler] = (*MemoryRepo[schema.Tabler])(nil)
+)
+
 type RepoOptions struct {
 	IdField string
+	// DefaultOrdering is used by List and Get when ordering is given neither
+	// by options nor by ordering field of filter
+	DefaultOrdering []Order
+	// MaxRowsAffected limits number of rows affected by update or delete,
+	// mutations are run in a transaction which is rolled back if it is
+	// exceeded. Zero means no limit.
+	MaxRowsAffected int64
+	// VersionField is version column used for optimistic locking, model
+	// field can be tagged with `repository:"version"` instead
+	VersionField string
 }
 
 type RepoBase[T schema.Tabler] struct {
-	IdField string
-	dbConn  *gorm.DB
+	IdField         string
+	DefaultOrdering []Order
+	MaxRowsAffected int64
+	VersionField    string
+	dbConn          *gorm.DB
 
 	ListMethod[T]
 	GetMethod[T]
@@ -36,17 +74,22 @@ func (repo *RepoBase[T]) InitMethods(methods []MethodInitInterface[T]) {
 
 func (m *RepoBase[T]) Init(dbConn *gorm.DB, options *RepoOptions) {
 	m.dbConn = dbConn
+	m.IdField = DEFAULT_ID_FIELD
 
-	if options == nil {
-		// no options provided? set defaults
-		m.IdField = DEFAULT_ID_FIELD
-	} else {
+	if options != nil {
 		if len(options.IdField) > 0 {
 			m.IdField = options.IdField
 		}
+		m.DefaultOrdering = options.DefaultOrdering
+		m.MaxRowsAffected = options.MaxRowsAffected
+		m.VersionField = options.VersionField
 	}
 
-	methods := []MethodInitInterface[T]{
+	m.InitMethods(m.methods())
+}
+
+func (m *RepoBase[T]) methods() []MethodInitInterface[T] {
+	return []MethodInitInterface[T]{
 		&m.ListMethod,
 		&m.GetMethod,
 		&m.ExistsMethod,
@@ -55,5 +98,13 @@ func (m *RepoBase[T]) Init(dbConn *gorm.DB, options *RepoOptions) {
 		&m.UpdateMethod,
 		&m.DeleteMethod,
 	}
-	m.InitMethods(methods)
+}
+
+// withConnection returns copy of repository, with the same options and
+// hooks, using given database connection
+func (m *RepoBase[T]) withConnection(dbConn *gorm.DB) *RepoBase[T] {
+	repo := *m
+	repo.dbConn = dbConn
+	repo.InitMethods(repo.methods())
+	return &repo
 }
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type ReturningOptions struct {
+	// Only lists returned columns, all columns are returned by default
+	Only []string
+}
+
+func returningOnly(options *ReturningOptions) []string {
+	if options == nil {
+		return nil
+	}
+	return options.Only
+}
+
+// supportsReturning reports whether database returns affected rows by
+// RETURNING clause. MySQL doesn't, affected rows are selected instead.
+func supportsReturning(query *gorm.DB) bool {
+	return query.Dialector.Name() != "mysql"
+}
+
+func returningClause(options *ReturningOptions) clause.Returning {
+	returning := clause.Returning{}
+	for _, column := range returningOnly(options) {
+		returning.Columns = append(returning.Columns, clause.Column{Name: column})
+	}
+	return returning
+}
+
+// lockedKeys selects and locks ids of models matching filter, they are
+// used by mutations which can't return affected rows
+func lockedKeys[T schema.Tabler](tx *gorm.DB, idField string, filter interface{}) ([]interface{}, error) {
+	var model T
+	query, err := mutationQuery[T](tx, filter, false)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []interface{}{}
+	result := query.Model(&model).Clauses(clause.Locking{Strength: "UPDATE"}).Pluck(idField, &keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+// selectByKeys selects models with given ids
+func selectByKeys[T schema.Tabler](tx *gorm.DB, idField string, keys []interface{}, options *ReturningOptions) ([]T, error) {
+	var model T
+	models := []T{}
+	if len(keys) == 0 {
+		return models, nil
+	}
+
+	query := ApplyOptionOnly(tx.Model(&model), returningOnly(options))
+	result := query.Where(clause.IN{Column: clause.Column{Table: model.TableName(), Name: idField}, Values: keys}).Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return models, nil
+}
+
+// modelKey returns id field value of model
+func modelKey[T schema.Tabler](query *gorm.DB, idField string, model *T) (interface{}, error) {
+	modelSchema, err := parseSchema(query, model)
+	if err != nil {
+		return nil, err
+	}
+	field := modelSchema.LookUpField(idField)
+	if field == nil {
+		return nil, fmt.Errorf("unknown column: %s", idField)
+	}
+	value, _ := field.ValueOf(context.Background(), reflect.ValueOf(model).Elem())
+	return value, nil
+}
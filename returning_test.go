@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturning(t *testing.T) {
+	cnt := 10
+	filter := MyModelFilter{CntGT: &cnt}
+
+	t.Run("Update returning", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		id := uuid.New()
+		sql := "UPDATE my_models SET cnt=$1 WHERE my_models.cnt > $2 RETURNING *"
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(111, cnt).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "value", "cnt"}).AddRow(id, "value", 111))
+		mock.ExpectCommit()
+
+		models, err := repo.UpdateReturning(filter, map[string]any{"cnt": 111}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []MyModel{{Id: &id, Value: "value", Cnt: 111}}, *models)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Returning only columns", func(t *testing.T) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+		options := &ReturningOptions{Only: []string{"id", "cnt"}}
+
+		statement, err := repo.ToSQL().UpdateReturning(filter, map[string]any{"cnt": 1}, options)
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE my_models SET cnt=$1 WHERE my_models.cnt > $2 RETURNING id,cnt", statement.SQL)
+
+		statement, err = repo.ToSQL().DeleteReturning(filter, options)
+		assert.Nil(t, err)
+		assert.Equal(t, "DELETE FROM my_models WHERE my_models.cnt > $1 RETURNING id,cnt", statement.SQL)
+
+		statement, err = repo.ToSQL().SaveReturning(&MyModel{Value: "x"}, options)
+		assert.Nil(t, err)
+		assert.Equal(t, "INSERT INTO my_models (id,value,cnt) VALUES ($1,$2,$3) RETURNING id,cnt", statement.SQL)
+	})
+
+	t.Run("Save returning runs hooks after save", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		saved := false
+		repo.PostSave = func(model *MyModel) error {
+			saved = true
+			return nil
+		}
+
+		id := uuid.New()
+		model := MyModel{Value: "value"}
+		sql := "INSERT INTO my_models (id,value,cnt) VALUES ($1,$2,$3) RETURNING *"
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(nil, model.Value, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "value", "cnt"}).AddRow(id, "value", 5))
+		mock.ExpectCommit()
+
+		_, err := repo.SaveReturning(&model, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, MyModel{Id: &id, Value: "value", Cnt: 5}, model)
+		assert.True(t, saved)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("MySQL selects affected rows", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+		db.Dialector = dialectOverride{Dialector: db.Dialector, name: "mysql"}
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		id := uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(
+			"SELECT `id` FROM `my_models` WHERE my_models.cnt > $1 FOR UPDATE",
+		))).
+			WithArgs(cnt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(
+			"SELECT `id`,`value` FROM `my_models` WHERE `my_models`.`id` = $1",
+		))).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "value"}).AddRow(id, "value"))
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(
+			"DELETE FROM `my_models` WHERE `id` = $1",
+		))).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		models, err := repo.DeleteReturning(filter, &ReturningOptions{Only: []string{"id", "value"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []MyModel{{Id: &id, Value: "value"}}, *models)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Memory repository", func(t *testing.T) {
+		repo := MemoryRepo[MyModel]{}
+		repo.Init(nil)
+		id := uuid.New()
+		_, err := repo.Save(&MyModel{Id: &id, Value: "value", Cnt: 20})
+		assert.Nil(t, err)
+
+		models, err := repo.UpdateReturning(filter, map[string]any{"cnt": 30}, &ReturningOptions{Only: []string{"id", "cnt"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []MyModel{{Id: &id, Cnt: 30}}, *models)
+
+		models, err = repo.DeleteReturning(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []MyModel{{Id: &id, Value: "value", Cnt: 30}}, *models)
+
+		_, err = repo.SaveReturning(&MyModel{}, &ReturningOptions{Only: []string{"unknown"}})
+		assert.EqualError(t, err, "unknown column: unknown")
+	})
+}
@@ -0,0 +1,126 @@
+package smartfilter
+
+import (
+	"slices"
+)
+
+// Builder builds filter conditions at runtime, without declaring filter
+// struct. Builder can be passed to ToQuery and repository methods in place
+// of filter struct:
+//
+//	filter := smartfilter.New().
+//		Where("status", smartfilter.OperatorIN, []string{"active", "pending"}).
+//		Where("cnt", smartfilter.OperatorGT, 5).
+//		Or("priority", smartfilter.OperatorEQ, "high")
+//
+// Conditions are combined left to right, the example above results in
+// (status IN (...) AND cnt > 5) OR priority = 'high'. Field names are
+// column names, the same as in filterfield tags.
+type Builder struct {
+	condition *Condition
+	err       error
+}
+
+func New() *Builder {
+	return &Builder{}
+}
+
+func (b *Builder) filterCondition() (*Condition, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.condition == nil {
+		return &Condition{}, nil
+	}
+	return b.condition, nil
+}
+
+// Err returns first error found while building conditions
+func (b *Builder) Err() error {
+	return b.err
+}
+
+func (b *Builder) field(field string, operator Operator, value interface{}) *Condition {
+	filterField, err := newConditionField(field, operator, value)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return nil
+	}
+	return &Condition{Field: filterField}
+}
+
+func (b *Builder) group(other *Builder) *Condition {
+	condition, err := other.filterCondition()
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return nil
+	}
+	return condition
+}
+
+func (b *Builder) and(condition *Condition) *Builder {
+	switch {
+	case condition == nil:
+	case b.condition == nil:
+		b.condition = condition
+	case b.condition.And != nil:
+		b.condition = &Condition{And: append(slices.Clone(b.condition.And), condition)}
+	default:
+		b.condition = &Condition{And: []*Condition{b.condition, condition}}
+	}
+	return b
+}
+
+func (b *Builder) or(condition *Condition) *Builder {
+	switch {
+	case condition == nil:
+	case b.condition == nil:
+		b.condition = condition
+	case b.condition.Or != nil:
+		b.condition = &Condition{Or: append(slices.Clone(b.condition.Or), condition)}
+	default:
+		b.condition = &Condition{Or: []*Condition{b.condition, condition}}
+	}
+	return b
+}
+
+func not(condition *Condition) *Condition {
+	if condition == nil {
+		return nil
+	}
+	return &Condition{Not: condition}
+}
+
+// Where adds condition, combined with AND
+func (b *Builder) Where(field string, operator Operator, value interface{}) *Builder {
+	return b.and(b.field(field, operator, value))
+}
+
+// Or adds condition, combined with OR
+func (b *Builder) Or(field string, operator Operator, value interface{}) *Builder {
+	return b.or(b.field(field, operator, value))
+}
+
+// Not adds negated condition, combined with AND
+func (b *Builder) Not(field string, operator Operator, value interface{}) *Builder {
+	return b.and(not(b.field(field, operator, value)))
+}
+
+// WhereGroup adds conditions of other builder as a group, combined with AND
+func (b *Builder) WhereGroup(other *Builder) *Builder {
+	return b.and(b.group(other))
+}
+
+// OrGroup adds conditions of other builder as a group, combined with OR
+func (b *Builder) OrGroup(other *Builder) *Builder {
+	return b.or(b.group(other))
+}
+
+// NotGroup adds negated conditions of other builder, combined with AND
+func (b *Builder) NotGroup(other *Builder) *Builder {
+	return b.and(not(b.group(other)))
+}
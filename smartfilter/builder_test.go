@@ -0,0 +1,114 @@
+package smartfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func builderToSQL(t *testing.T, filter interface{}) (string, error) {
+	db, _ := NewMockDB()
+	var err error
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var query *gorm.DB
+		query, err = ToQuery(MyModel{}, filter, tx.Model(&MyModel{}))
+		if err != nil {
+			return tx
+		}
+		return query.Find(&[]MyModel{})
+	})
+	return sql, err
+}
+
+func TestBuilder(t *testing.T) {
+	t.Run("Empty builder", func(t *testing.T) {
+		sql, err := builderToSQL(t, New())
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models", sql)
+	})
+
+	t.Run("Combine left to right", func(t *testing.T) {
+		filter := New().
+			Where("status", OperatorIN, []string{"active", "pending"}).
+			Where("cnt", OperatorGT, 5).
+			Or("priority", OperatorEQ, "high")
+
+		sql, err := builderToSQL(t, filter)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT * FROM my_models WHERE ((my_models.status IN ('active','pending') AND my_models.cnt > 5) OR my_models.priority = 'high')",
+			sql,
+		)
+	})
+
+	t.Run("Groups and negation", func(t *testing.T) {
+		cnt := 10
+		filter := New().
+			Not("status", OperatorEQ, "deleted").
+			WhereGroup(New().Where("cnt", OperatorLT, &cnt).Or("cnt", OperatorGT, 100)).
+			NotGroup(New().Where("attrs->>color", OperatorIEQ, "Red"))
+
+		sql, err := builderToSQL(t, filter)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT * FROM my_models WHERE NOT my_models.status = 'deleted' AND (my_models.cnt < 10 OR my_models.cnt > 100) AND NOT LOWER(my_models.attrs->>'color') = 'red'",
+			sql,
+		)
+	})
+
+	t.Run("Groups do not share conditions", func(t *testing.T) {
+		group := New().Where("cnt", OperatorGT, 1).Where("cnt", OperatorLT, 5)
+		filter := New().WhereGroup(group).Where("status", OperatorEQ, "active")
+
+		sql, err := builderToSQL(t, group)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.cnt > 1 AND my_models.cnt < 5", sql)
+
+		sql, err = builderToSQL(t, filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.cnt > 1 AND my_models.cnt < 5 AND my_models.status = 'active'", sql)
+	})
+
+	t.Run("Fail on unknown operator", func(t *testing.T) {
+		filter := New().Where("cnt", Operator("ROUGHLY"), 5).Where("status", OperatorEQ, "active")
+		assert.EqualError(t, filter.Err(), "unknown operator: ROUGHLY")
+
+		_, err := builderToSQL(t, filter)
+		assert.EqualError(t, err, "unknown operator: ROUGHLY")
+	})
+
+	t.Run("Fail on invalid value type", func(t *testing.T) {
+		_, err := builderToSQL(t, New().Where("status", OperatorLIKE, 5))
+		assert.EqualError(t, err, "invalid field type for operator LIKE")
+	})
+
+	t.Run("Fail on invalid field name", func(t *testing.T) {
+		for _, column := range []string{"id = 1 OR 1=1 --", "my_models.id", "attrs) OR (1=1->>color"} {
+			filter := New().Where(column, OperatorEQ, 1)
+			assert.EqualError(t, filter.Err(), "invalid field name: "+column)
+		}
+
+		filter := New().Where("title, body; DROP TABLE my_models", OperatorSEARCH, "fox")
+		assert.EqualError(t, filter.Err(), "invalid field name: title, body; DROP TABLE my_models")
+
+		filter = New().Where("title, body", OperatorSEARCH, "fox").Where("attrs->meta->>color", OperatorEQ, "red")
+		assert.Nil(t, filter.Err())
+	})
+
+	t.Run("Fail on value shape of operator", func(t *testing.T) {
+		filter := New().Where("name", OperatorLIKE, []string{"x"})
+		assert.EqualError(t, filter.Err(), "name: operator LIKE requires a single value")
+
+		filter = New().Where("cnt", OperatorIN, 1)
+		assert.EqualError(t, filter.Err(), "cnt: operator IN requires a list value")
+	})
+
+	t.Run("Fail on missing value", func(t *testing.T) {
+		var cnt *int
+		filter := New().Where("cnt", OperatorEQ, cnt)
+		assert.EqualError(t, filter.Err(), "cnt: missing value for operator EQ")
+	})
+}
@@ -0,0 +1,203 @@
+package smartfilter
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Condition is a node of filter expression built at runtime, rather than
+// declared by filterfield struct tags. Leaf nodes hold a single filter field,
+// other nodes combine their children with AND, OR or NOT.
+type Condition struct {
+	Field *FilterField
+	And   []*Condition
+	Or    []*Condition
+	Not   *Condition
+}
+
+// conditionFilter is implemented by runtime built filters, ToQuery applies
+// their conditions instead of reflecting struct tags
+type conditionFilter interface {
+	filterCondition() (*Condition, error)
+}
+
+// FieldAllowlist maps public field names, used by runtime built filters,
+// to column names. Column names may contain json path.
+type FieldAllowlist map[string]string
+
+var modelSchemaCache = sync.Map{}
+
+func parseModelSchema(model schema.Tabler) (*schema.Schema, error) {
+	return schema.Parse(model, &modelSchemaCache, schema.NamingStrategy{})
+}
+
+// ModelAllowlist allows all columns of model, using column names as
+// public field names
+func ModelAllowlist(model schema.Tabler) (FieldAllowlist, error) {
+	modelSchema, err := parseModelSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist := FieldAllowlist{}
+	for _, field := range modelSchema.Fields {
+		if len(field.DBName) > 0 {
+			allowlist[field.DBName] = field.DBName
+		}
+	}
+	return allowlist, nil
+}
+
+// resolve returns column name of public field name
+func (a FieldAllowlist) resolve(name string) (string, error) {
+	column, ok := a[name]
+	if !ok {
+		return "", fmt.Errorf("field not allowed: %s", name)
+	}
+	return column, nil
+}
+
+// newConditionField creates filter field for given column, operator and value,
+// validated the same way as filterfield struct tags
+func newConditionField(column string, operator Operator, value interface{}) (*FilterField, error) {
+	if len(column) == 0 {
+		return nil, fmt.Errorf("missing field name")
+	}
+	if !slices.Contains(OPERATORS, operator) {
+		return nil, fmt.Errorf("unknown operator: %s", operator)
+	}
+	if strings.Contains(column, TAG_LIST_SEPARATOR) && operator != OperatorSEARCH {
+		return nil, fmt.Errorf("multiple fields are supported only by %s operator", OperatorSEARCH)
+	}
+	path, err := parseJSONPath(column)
+	if err != nil {
+		return nil, err
+	}
+	// column names are embedded into SQL
+	names := []string{column}
+	if path != nil {
+		names = []string{path.column}
+	} else if operator == OperatorSEARCH {
+		names = strings.Split(column, TAG_LIST_SEPARATOR)
+	}
+	for _, name := range names {
+		if !IsIdentifier(strings.TrimSpace(name)) {
+			return nil, fmt.Errorf("invalid field name: %s", column)
+		}
+	}
+
+	filterField := FilterField{
+		Name:     column,
+		Operator: operator,
+		jsonPath: path,
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return nil, fmt.Errorf("%s: missing value for operator %s", column, operator)
+	}
+	err = filterField.setValueFromReflection(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", column, err)
+	}
+	if err := filterField.checkValueShape(); err != nil {
+		return nil, fmt.Errorf("%s: %s", column, err)
+	}
+	return &filterField, nil
+}
+
+// empty reports whether condition has no filter fields
+func (c *Condition) empty() bool {
+	if c == nil {
+		return true
+	}
+	if c.Field != nil {
+		return false
+	}
+	for _, children := range [][]*Condition{c.And, c.Or, {c.Not}} {
+		for _, child := range children {
+			if !child.empty() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// expression builds condition into a single clause expression
+func (c *Condition) expression(query *gorm.DB, tableName string) (clause.Expression, error) {
+	switch {
+	case c.Field != nil:
+		group, err := applyFilterField(query.Session(&gorm.Session{NewDB: true}), tableName, c.Field)
+		if err != nil {
+			return nil, err
+		}
+		if group.Error != nil {
+			return nil, group.Error
+		}
+		cs, ok := group.Statement.Clauses["WHERE"]
+		if !ok {
+			return nil, nil
+		}
+		where, ok := cs.Expression.(clause.Where)
+		if !ok {
+			return nil, nil
+		}
+		return clause.And(where.Exprs...), nil
+
+	case c.Not != nil:
+		expr, err := c.Not.expression(query, tableName)
+		if err != nil || expr == nil {
+			return nil, err
+		}
+		return clause.Not(expr), nil
+
+	case len(c.And) > 0:
+		exprs, err := childExpressions(query, tableName, c.And)
+		if err != nil {
+			return nil, err
+		}
+		return clause.And(exprs...), nil
+
+	case len(c.Or) > 0:
+		exprs, err := childExpressions(query, tableName, c.Or)
+		if err != nil {
+			return nil, err
+		}
+		return clause.Or(exprs...), nil
+	}
+	return nil, nil
+}
+
+func childExpressions(query *gorm.DB, tableName string, children []*Condition) ([]clause.Expression, error) {
+	exprs := make([]clause.Expression, 0, len(children))
+	for _, child := range children {
+		expr, err := child.expression(query, tableName)
+		if err != nil {
+			return nil, err
+		}
+		if expr != nil {
+			exprs = append(exprs, expr)
+		}
+	}
+	return exprs, nil
+}
+
+// apply adds condition to query
+func (c *Condition) apply(query *gorm.DB, tableName string) (*gorm.DB, error) {
+	expr, err := c.expression(query, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if expr == nil {
+		return query, nil
+	}
+	return query.Where(expr), nil
+}
@@ -0,0 +1,53 @@
+package smartfilter
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	dialectPostgres = "postgres"
+	dialectMySQL    = "mysql"
+	dialectSQLite   = "sqlite"
+)
+
+func dialectName(query *gorm.DB) string {
+	if query == nil || query.Dialector == nil {
+		return ""
+	}
+	return query.Dialector.Name()
+}
+
+// placeholders returns n comma separated bind placeholders. Used where gorm
+// would otherwise expand a slice variable into a parenthesized list, which
+// is not valid inside ARRAY[...] constructors or function calls.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// sqlOperator returns an expression which renders given SQL verbatim. It is
+// used to emit operators containing "?" (e.g. jsonb "?|") which gorm would
+// otherwise treat as a bind placeholder.
+func sqlOperator(operator string) clause.Expr {
+	return clause.Expr{SQL: operator}
+}
+
+// columnName returns the column expression used in conditions, including
+// json path traversal if filter field targets a json document key.
+func columnName(query *gorm.DB, tableName string, filterField *FilterField) string {
+	if filterField.jsonPath == nil {
+		return fmt.Sprintf("%s.%s", tableName, filterField.Name)
+	}
+	return filterField.jsonPath.expression(dialectName(query), tableName)
+}
+
+// WithError adds error to a new query instance, errors must not be added
+// to the shared connection
+func WithError(query *gorm.DB, err error) *gorm.DB {
+	query = query.Session(&gorm.Session{})
+	query.AddError(err)
+	return query
+}
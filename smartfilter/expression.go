@@ -0,0 +1,453 @@
+package smartfilter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Filter expression grammar, keywords are case insensitive:
+//
+//	expression = and_expr { "or" and_expr }
+//	and_expr   = unary { "and" unary }
+//	unary      = "not" unary | "(" expression ")" | comparison
+//	comparison = field operator value
+//	           | field [ "not" ] "in" "(" value { "," value } ")"
+//	           | field "between" value "and" value
+//	operator   = "=" | "!=" | "<>" | ">" | ">=" | "<" | "<=" | "~"
+//	           | "like" | "ilike" | "search"
+//	value      = quoted string | bare word
+//
+// Quoted strings use single or double quotes, backslash escapes the next
+// character, their value is always a string. Bare words are converted to
+// bool (true, false), integer, float, time (QUERY_TIME_FORMATS) or uuid if
+// possible, otherwise they are strings. Operator "~" is ILIKE. Example:
+//
+//	status in (active, pending) and created_at >= 2024-01-01 and not name ~ "test"
+
+var expressionFieldRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+var expressionOperators = map[string]Operator{
+	"=":      OperatorEQ,
+	"!=":     OperatorNE,
+	"<>":     OperatorNE,
+	">":      OperatorGT,
+	">=":     OperatorGE,
+	"<":      OperatorLT,
+	"<=":     OperatorLE,
+	"~":      OperatorILIKE,
+	"like":   OperatorLIKE,
+	"ilike":  OperatorILIKE,
+	"search": OperatorSEARCH,
+}
+
+const expressionSymbols = "=!<>~"
+
+// maximum nesting of groups and negations, expressions are user input and
+// parsed recursively
+const EXPRESSION_MAX_DEPTH = 32
+
+// ParseError describes invalid filter expression. Position is 1-based
+// character position in expression.
+type ParseError struct {
+	Position int
+	Message  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Position, e.Message)
+}
+
+type expressionTokenKind int
+
+const (
+	tokenEOF expressionTokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type expressionToken struct {
+	kind     expressionTokenKind
+	text     string
+	position int
+}
+
+func (t expressionToken) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (t expressionToken) describe() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+func tokenizeExpression(input string) ([]expressionToken, error) {
+	runes := []rune(input)
+	tokens := []expressionToken{}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, expressionToken{kind: tokenLParen, text: "(", position: start + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, expressionToken{kind: tokenRParen, text: ")", position: start + 1})
+			i++
+		case r == ',':
+			tokens = append(tokens, expressionToken{kind: tokenComma, text: ",", position: start + 1})
+			i++
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &ParseError{Position: start + 1, Message: "unterminated string"}
+			}
+			tokens = append(tokens, expressionToken{kind: tokenString, text: sb.String(), position: start + 1})
+		case strings.ContainsRune(expressionSymbols, r):
+			for i < len(runes) && strings.ContainsRune(expressionSymbols, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, expressionToken{kind: tokenSymbol, text: string(runes[start:i]), position: start + 1})
+		default:
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`(),"'`+expressionSymbols, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, expressionToken{kind: tokenWord, text: string(runes[start:i]), position: start + 1})
+		}
+	}
+
+	tokens = append(tokens, expressionToken{kind: tokenEOF, position: len(runes) + 1})
+	return tokens, nil
+}
+
+type expressionParser struct {
+	tokens    []expressionToken
+	current   int
+	depth     int
+	allowlist FieldAllowlist
+}
+
+func (p *expressionParser) peek() expressionToken {
+	return p.tokens[p.current]
+}
+
+func (p *expressionParser) next() expressionToken {
+	token := p.tokens[p.current]
+	if token.kind != tokenEOF {
+		p.current++
+	}
+	return token
+}
+
+func (p *expressionParser) errorAt(token expressionToken, format string, args ...interface{}) error {
+	return &ParseError{Position: token.position, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *expressionParser) unexpected(token expressionToken, expected string) error {
+	return p.errorAt(token, "expected %s, got %s", expected, token.describe())
+}
+
+func (p *expressionParser) expect(kind expressionTokenKind, expected string) (expressionToken, error) {
+	token := p.next()
+	if token.kind != kind {
+		return token, p.unexpected(token, expected)
+	}
+	return token, nil
+}
+
+func (p *expressionParser) parseOr() (*Condition, error) {
+	conditions := []*Condition{}
+	for {
+		condition, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		if !p.peek().isKeyword("or") {
+			break
+		}
+		p.next()
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return &Condition{Or: conditions}, nil
+}
+
+func (p *expressionParser) parseAnd() (*Condition, error) {
+	conditions := []*Condition{}
+	for {
+		condition, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		if !p.peek().isKeyword("and") {
+			break
+		}
+		p.next()
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return &Condition{And: conditions}, nil
+}
+
+func (p *expressionParser) parseUnary() (*Condition, error) {
+	token := p.peek()
+	if token.isKeyword("not") || token.kind == tokenLParen {
+		if p.depth >= EXPRESSION_MAX_DEPTH {
+			return nil, p.errorAt(token, "expression is nested too deeply, max depth is %d", EXPRESSION_MAX_DEPTH)
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+	}
+
+	switch {
+	case token.isKeyword("not"):
+		p.next()
+		condition, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Condition{Not: condition}, nil
+	case token.kind == tokenLParen:
+		p.next()
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return condition, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *expressionParser) parseComparison() (*Condition, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != tokenWord || !expressionFieldRegexp.MatchString(fieldToken.text) {
+		return nil, p.unexpected(fieldToken, "field name")
+	}
+	column, err := p.allowlist.resolve(fieldToken.text)
+	if err != nil {
+		return nil, p.errorAt(fieldToken, "%s", err)
+	}
+
+	var operator Operator
+	var value interface{}
+
+	operatorToken := p.next()
+	switch {
+	case operatorToken.isKeyword("in"):
+		operator = OperatorIN
+		value, err = p.parseList()
+	case operatorToken.isKeyword("not"):
+		if in := p.next(); !in.isKeyword("in") {
+			return nil, p.unexpected(in, `"in"`)
+		}
+		operator = OperatorNOT_IN
+		value, err = p.parseList()
+	case operatorToken.isKeyword("between"):
+		operator = OperatorBETWEEN
+		value, err = p.parseBetween()
+	case operatorToken.kind == tokenSymbol || operatorToken.kind == tokenWord:
+		var ok bool
+		operator, ok = expressionOperators[strings.ToLower(operatorToken.text)]
+		if !ok {
+			return nil, p.errorAt(operatorToken, "unknown operator %s", operatorToken.describe())
+		}
+		value, err = p.parseValue()
+	default:
+		return nil, p.unexpected(operatorToken, "operator")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filterField, err := newConditionField(column, operator, value)
+	if err != nil {
+		return nil, p.errorAt(fieldToken, "%s", err)
+	}
+	return &Condition{Field: filterField}, nil
+}
+
+func (p *expressionParser) parseValue() (interface{}, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenString:
+		return token.text, nil
+	case tokenWord:
+		return expressionWordValue(token.text), nil
+	}
+	return nil, p.unexpected(token, "value")
+}
+
+func (p *expressionParser) parseList() (interface{}, error) {
+	open, err := p.expect(tokenLParen, `"("`)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		token := p.next()
+		if token.kind == tokenRParen {
+			break
+		}
+		if token.kind != tokenComma {
+			return nil, p.unexpected(token, `"," or ")"`)
+		}
+	}
+
+	list, err := expressionList(values)
+	if err != nil {
+		return nil, p.errorAt(open, "%s", err)
+	}
+	return list, nil
+}
+
+func (p *expressionParser) parseBetween() (interface{}, error) {
+	start := p.peek()
+	from, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if and := p.next(); !and.isKeyword("and") {
+		return nil, p.unexpected(and, `"and"`)
+	}
+	to, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := expressionList([]interface{}{from, to})
+	if err != nil {
+		return nil, p.errorAt(start, "%s", err)
+	}
+	return list, nil
+}
+
+// expressionWordValue converts bare word to the most specific type it
+// can be parsed as
+func expressionWordValue(word string) interface{} {
+	switch strings.ToLower(word) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.ParseInt(word, 10, 64); err == nil {
+		return i
+	}
+	// words like "inf" and "nan" are strings
+	if f, err := strconv.ParseFloat(word, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return f
+	}
+	if tm, err := parseTime(word); err == nil {
+		return tm
+	}
+	if id, err := uuid.Parse(word); err == nil {
+		return id
+	}
+	return word
+}
+
+// expressionList creates typed slice from list values. Integers are
+// converted to floats if list contains both.
+func expressionList(values []interface{}) (interface{}, error) {
+	elementType := reflect.TypeOf(values[0])
+	for _, value := range values[1:] {
+		t := reflect.TypeOf(value)
+		if t == elementType {
+			continue
+		}
+		if isExpressionNumber(t) && isExpressionNumber(elementType) {
+			elementType = reflect.TypeOf(float64(0))
+			continue
+		}
+		return nil, fmt.Errorf("list elements must be of the same type")
+	}
+
+	list := reflect.MakeSlice(reflect.SliceOf(elementType), 0, len(values))
+	for _, value := range values {
+		list = reflect.Append(list, reflect.ValueOf(value).Convert(elementType))
+	}
+	return list.Interface(), nil
+}
+
+func isExpressionNumber(t reflect.Type) bool {
+	return t.Kind() == reflect.Int64 || t.Kind() == reflect.Float64
+}
+
+// ExpressionFilter is a filter parsed from filter expression. It can be
+// passed to ToQuery and repository methods in place of filter struct.
+type ExpressionFilter struct {
+	condition *Condition
+}
+
+func (f *ExpressionFilter) filterCondition() (*Condition, error) {
+	return f.condition, nil
+}
+
+// ParseExpression parses filter expression. Only fields present in
+// allowlist may be used, they are translated to allowlisted columns.
+// Empty expression matches everything. Errors are returned as *ParseError.
+func ParseExpression(input string, allowlist FieldAllowlist) (*ExpressionFilter, error) {
+	tokens, err := tokenizeExpression(input)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokenEOF {
+		return &ExpressionFilter{condition: &Condition{}}, nil
+	}
+
+	parser := expressionParser{tokens: tokens, allowlist: allowlist}
+	condition, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tokenEOF {
+		return nil, parser.unexpected(token, `"and", "or" or end of expression`)
+	}
+	return &ExpressionFilter{condition: condition}, nil
+}
@@ -0,0 +1,110 @@
+package smartfilter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpression(t *testing.T) {
+	allowlist := FieldAllowlist{
+		"status":     "status",
+		"cnt":        "cnt",
+		"name":       "name",
+		"created_at": "created_at",
+		"color":      "attrs->>color",
+	}
+
+	t.Run("Parse expression", func(t *testing.T) {
+		filter, err := ParseExpression(
+			`status in (active, pending) and created_at >= 2024-01-01 and not name ~ "test"`, allowlist,
+		)
+		assert.Nil(t, err)
+
+		sql, err := builderToSQL(t, filter)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT * FROM my_models WHERE my_models.status IN ('active','pending') AND my_models.created_at >= '2024-01-01 00:00:00' AND NOT my_models.name ILIKE '%test%'",
+			sql,
+		)
+	})
+
+	t.Run("Operator precedence and groups", func(t *testing.T) {
+		filter, err := ParseExpression(
+			`cnt between 1 and 5 or COLOR = 'red' AND (cnt <> 3 or status not in ("a", "b"))`,
+			FieldAllowlist{"cnt": "cnt", "COLOR": "attrs->>color", "status": "status"},
+		)
+		assert.Nil(t, err)
+
+		sql, err := builderToSQL(t, filter)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT * FROM my_models WHERE ((my_models.cnt BETWEEN 1 AND 5) OR (my_models.attrs->>'color' = 'red' AND (my_models.cnt != 3 OR my_models.status NOT IN ('a','b'))))",
+			sql,
+		)
+	})
+
+	t.Run("Value types", func(t *testing.T) {
+		filter, err := ParseExpression(
+			`cnt in (1, 2.5) and status = "42" and name = true and name = inf`, allowlist,
+		)
+		assert.Nil(t, err)
+
+		sql, err := builderToSQL(t, filter)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT * FROM my_models WHERE my_models.cnt IN (1,2.5) AND my_models.status = '42' AND my_models.name = true AND my_models.name = 'inf'",
+			sql,
+		)
+	})
+
+	t.Run("Empty expression", func(t *testing.T) {
+		filter, err := ParseExpression("  ", allowlist)
+		assert.Nil(t, err)
+
+		sql, err := builderToSQL(t, filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models", sql)
+	})
+
+	t.Run("Report errors with position", func(t *testing.T) {
+		cases := []struct {
+			expression string
+			err        string
+		}{
+			{`password = "x"`, "position 1: field not allowed: password"},
+			{`status == active`, `position 8: unknown operator "=="`},
+			{`status = active and`, "position 20: expected field name, got end of expression"},
+			{`(cnt > 1`, "position 9: expected \")\", got end of expression"},
+			{`cnt > 1 cnt < 2`, `position 9: expected "and", "or" or end of expression, got "cnt"`},
+			{`name = "test`, "position 8: unterminated string"},
+			{`cnt in (1, a)`, "position 8: list elements must be of the same type"},
+			{`cnt not like 1`, `position 9: expected "in", got "like"`},
+			{`cnt between 1 or 2`, `position 15: expected "and", got "or"`},
+			{`color, cnt search x`, `position 6: expected operator, got ","`},
+		}
+		for _, c := range cases {
+			filter, err := ParseExpression(c.expression, allowlist)
+			assert.Nil(t, filter, c.expression)
+			assert.EqualError(t, err, c.err, c.expression)
+			assert.IsType(t, &ParseError{}, err, c.expression)
+		}
+	})
+
+	t.Run("Fail on deeply nested expression", func(t *testing.T) {
+		nested := strings.Repeat("(", EXPRESSION_MAX_DEPTH) + "cnt > 1" + strings.Repeat(")", EXPRESSION_MAX_DEPTH)
+		_, err := ParseExpression(nested, allowlist)
+		assert.Nil(t, err)
+
+		_, err = ParseExpression("("+nested+")", allowlist)
+		assert.EqualError(t, err, "position 33: expression is nested too deeply, max depth is 32")
+		assert.IsType(t, &ParseError{}, err)
+
+		_, err = ParseExpression(strings.Repeat("not ", 100000)+"cnt > 1", allowlist)
+		assert.EqualError(t, err, "position 129: expression is nested too deeply, max depth is 32")
+	})
+}
@@ -1,98 +1,207 @@
 package smartfilter
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Time and uuid values are bound natively to the driver, their value kinds
+// are therefore tracked separately from the plain string kind.
+const (
+	valueKindTime = reflect.Struct
+	valueKindUUID = reflect.Array
+	// maps and structs are marshalled into a json document
+	valueKindJSON = reflect.Map
+)
+
 type FilterField struct {
 	Name     string
 	Operator Operator
-
+	// Table, if set, qualifies column instead of model table, e.g. with
+	// name or alias of joined table
+	Table string
+	// Location, if set, converts time values before binding
+	Location *time.Location
+	// SearchConfig is text search configuration used by SEARCH operator
+	SearchConfig string
+	// CIText marks case-insensitive column type, IEQ, INE and IIN operators
+	// then compare values as they are
+	CIText bool
+
+	jsonPath    *jsonPath
 	valueKind   reflect.Kind
 	boolValue   *bool
 	intValue    *int64
 	uintValue   *uint64
 	floatValue  *float64
 	strValue    *string
+	timeValue   *time.Time
+	uuidValue   *uuid.UUID
+	jsonValue   *string
 	boolValues  *[]bool
 	intValues   *[]int64
 	uintValues  *[]uint64
 	floatValues *[]float64
 	strValues   *[]string
+	timeValues  *[]time.Time
+	uuidValues  *[]uuid.UUID
 }
 
-func (ff *FilterField) setValueFromReflection(v reflect.Value) {
+func (ff *FilterField) setValueFromReflection(v reflect.Value) error {
 	fn := typeGetter(v.Type())
-	fn(ff, v)
+	return fn(ff, v)
 }
 
 func (ff *FilterField) appendStr(value string) {
-	var valueArray []string
-
-	if ff.strValues == nil {
-		valueArray = make([]string, 0)
-	} else {
-		valueArray = *ff.strValues
-	}
-	valueArray = append(valueArray, value)
-	ff.strValues = &valueArray
+	appendValue(&ff.strValues, value)
 	ff.valueKind = reflect.String
 }
 
 func (ff *FilterField) appendBool(value bool) {
-	var valueArray []bool
-
-	if ff.boolValues == nil {
-		valueArray = make([]bool, 0)
-	} else {
-		valueArray = *ff.boolValues
-	}
-	valueArray = append(valueArray, value)
-	ff.boolValues = &valueArray
+	appendValue(&ff.boolValues, value)
 	ff.valueKind = reflect.Bool
 }
 
 func (ff *FilterField) appendInt(value int64) {
-	var valueArray []int64
-
-	if ff.boolValues == nil {
-		valueArray = make([]int64, 0)
-	} else {
-		valueArray = *ff.intValues
-	}
-	valueArray = append(valueArray, value)
-	ff.intValues = &valueArray
+	appendValue(&ff.intValues, value)
 	ff.valueKind = reflect.Int
 }
 
 func (ff *FilterField) appendUint(value uint64) {
-	var valueArray []uint64
+	appendValue(&ff.uintValues, value)
+	ff.valueKind = reflect.Uint
+}
 
-	if ff.boolValues == nil {
-		valueArray = make([]uint64, 0)
-	} else {
-		valueArray = *ff.uintValues
+func (ff *FilterField) appendFloat(value float64) {
+	appendValue(&ff.floatValues, value)
+	ff.valueKind = reflect.Float64
+}
+
+func (ff *FilterField) appendTime(value time.Time) {
+	appendValue(&ff.timeValues, ff.localizeTime(value))
+	ff.valueKind = valueKindTime
+}
+
+func (ff *FilterField) appendUUID(value uuid.UUID) {
+	appendValue(&ff.uuidValues, value)
+	ff.valueKind = valueKindUUID
+}
+
+func initValues[T any](values **[]T) {
+	if *values == nil {
+		valueArray := make([]T, 0)
+		*values = &valueArray
 	}
-	valueArray = append(valueArray, value)
-	ff.uintValues = &valueArray
-	ff.valueKind = reflect.Int
 }
 
-func (ff *FilterField) appendFloat(value float64) {
-	var valueArray []float64
+func appendValue[T any](values **[]T, value T) {
+	initValues(values)
+	**values = append(**values, value)
+}
 
-	if ff.boolValues == nil {
-		valueArray = make([]float64, 0)
-	} else {
-		valueArray = *ff.floatValues
+// localizeTime converts time value to the location requested by the
+// timezone tag option. Values are left untouched if no location is set.
+func (ff *FilterField) localizeTime(value time.Time) time.Time {
+	if ff.Location == nil {
+		return value
 	}
-	valueArray = append(valueArray, value)
-	ff.floatValues = &valueArray
-	ff.valueKind = reflect.Int
+	return value.In(ff.Location)
+}
+
+// value returns filter value as it is bound to the driver, either a single
+// value or a slice of values.
+func (ff *FilterField) value() interface{} {
+	if value := ff.scalarValue(); value != nil {
+		return value
+	}
+	return ff.sliceValue()
+}
+
+func (ff *FilterField) scalarValue() interface{} {
+	switch {
+	case ff.boolValue != nil:
+		return *ff.boolValue
+	case ff.intValue != nil:
+		return *ff.intValue
+	case ff.uintValue != nil:
+		return *ff.uintValue
+	case ff.floatValue != nil:
+		return *ff.floatValue
+	case ff.strValue != nil:
+		return *ff.strValue
+	case ff.timeValue != nil:
+		return *ff.timeValue
+	case ff.uuidValue != nil:
+		return *ff.uuidValue
+	}
+	return nil
+}
+
+func (ff *FilterField) sliceValue() interface{} {
+	switch ff.valueKind {
+	case reflect.Bool:
+		if ff.boolValues != nil {
+			return *ff.boolValues
+		}
+	case reflect.Int:
+		if ff.intValues != nil {
+			return *ff.intValues
+		}
+	case reflect.Uint:
+		if ff.uintValues != nil {
+			return *ff.uintValues
+		}
+	case reflect.Float64:
+		if ff.floatValues != nil {
+			return *ff.floatValues
+		}
+	case reflect.String:
+		if ff.strValues != nil {
+			return *ff.strValues
+		}
+	case valueKindTime:
+		if ff.timeValues != nil {
+			return *ff.timeValues
+		}
+	case valueKindUUID:
+		if ff.uuidValues != nil {
+			return *ff.uuidValues
+		}
+	}
+	return nil
+}
+
+// checkValueShape rejects a list value of single value operator and vice
+// versa, operator handlers rely on it
+func (ff *FilterField) checkValueShape() error {
+	switch {
+	case ff.Operator == OperatorJSON_CONTAINS:
+		return nil
+	case slices.Contains(listOperators, ff.Operator):
+		if ff.sliceValue() == nil {
+			return fmt.Errorf("operator %s requires a list value", ff.Operator)
+		}
+	case ff.scalarValue() == nil:
+		return fmt.Errorf("operator %s requires a single value", ff.Operator)
+	}
+	return nil
+}
+
+// jsonDocument returns filter value marshalled as json document
+func (ff *FilterField) jsonDocument() (string, error) {
+	if ff.jsonValue != nil {
+		return *ff.jsonValue, nil
+	}
+	document, err := json.Marshal(ff.value())
+	if err != nil {
+		return "", err
+	}
+	return string(document), nil
 }
 
 type valueGetterFunc func(ff *FilterField, v reflect.Value) error
@@ -133,39 +242,35 @@ func strValueGetter(ff *FilterField, v reflect.Value) error {
 }
 
 func timeValueGetter(ff *FilterField, v reflect.Value) error {
-	value, err := timeValueToStr(v)
-	if err != nil {
-		return err
+	value, ok := v.Interface().(time.Time)
+	if !ok {
+		return fmt.Errorf("error converting interface to time")
 	}
-	ff.strValue = &value
-	ff.valueKind = reflect.String
+	value = ff.localizeTime(value)
+	ff.timeValue = &value
+	ff.valueKind = valueKindTime
 	return nil
 }
 
 func uuidValueGetter(ff *FilterField, v reflect.Value) error {
-	value, err := uuidValueToStr(v)
-	if err != nil {
-		return err
-	}
-	ff.strValue = &value
-	ff.valueKind = reflect.String
-	return nil
-}
-
-func timeValueToStr(v reflect.Value) (string, error) {
-	value, ok := v.Interface().(time.Time)
+	value, ok := v.Interface().(uuid.UUID)
 	if !ok {
-		return "", fmt.Errorf("error converting interface to time")
+		return fmt.Errorf("error converting interface to uuid")
 	}
-	return value.Format(time.RFC3339), nil
+	ff.uuidValue = &value
+	ff.valueKind = valueKindUUID
+	return nil
 }
 
-func uuidValueToStr(v reflect.Value) (string, error) {
-	value, ok := v.Interface().(uuid.UUID)
-	if !ok {
-		return "", fmt.Errorf("error converting interface to uuid")
+func jsonValueGetter(ff *FilterField, v reflect.Value) error {
+	document, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
 	}
-	return value.String(), nil
+	value := string(document)
+	ff.jsonValue = &value
+	ff.valueKind = valueKindJSON
+	return nil
 }
 
 func unsupportedValueGetter(ff *FilterField, v reflect.Value) error {
@@ -212,8 +317,9 @@ func newTypeGetter(t reflect.Type, allowAddr bool) valueGetterFunc {
 		if t == reflect.TypeOf(time.Time{}) {
 			return timeValueGetter
 		}
-	// case reflect.Map:
-	// 	return newMapEncoder(t)
+		return jsonValueGetter
+	case reflect.Map:
+		return jsonValueGetter
 	case reflect.Slice:
 		return newSliceGetter(t)
 	case reflect.Array:
@@ -232,8 +338,7 @@ type ptrValueGetter struct {
 }
 
 func (pvg ptrValueGetter) getValue(ff *FilterField, v reflect.Value) error {
-	pvg.elemGetter(ff, v.Elem())
-	return nil
+	return pvg.elemGetter(ff, v.Elem())
 }
 
 func newPtrValueGetter(t reflect.Type) valueGetterFunc {
@@ -246,8 +351,7 @@ type arrayGetter struct {
 }
 
 func (ag arrayGetter) getValue(ff *FilterField, v reflect.Value) error {
-	ag.elemGetter(ff, v.Elem())
-	return nil
+	return ag.elemGetter(ff, v.Elem())
 }
 
 func newArrayGetter(t reflect.Type) valueGetterFunc {
@@ -260,6 +364,40 @@ type sliceGetter struct {
 }
 
 func (sg sliceGetter) getValue(ff *FilterField, v reflect.Value) error {
+	// value kind and values are set from the element type, even for empty slices
+	elemType := v.Type().Elem()
+	switch elemType.Kind() {
+	case reflect.Bool:
+		initValues(&ff.boolValues)
+		ff.valueKind = reflect.Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		initValues(&ff.intValues)
+		ff.valueKind = reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		initValues(&ff.uintValues)
+		ff.valueKind = reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		initValues(&ff.floatValues)
+		ff.valueKind = reflect.Float64
+	case reflect.String:
+		initValues(&ff.strValues)
+		ff.valueKind = reflect.String
+	case reflect.Struct:
+		if elemType != reflect.TypeOf(time.Time{}) {
+			return fmt.Errorf("unsupported slice element type: %v", elemType)
+		}
+		initValues(&ff.timeValues)
+		ff.valueKind = valueKindTime
+	case reflect.Array:
+		if elemType != reflect.TypeOf(uuid.UUID{}) {
+			return fmt.Errorf("unsupported slice element type: %v", elemType)
+		}
+		initValues(&ff.uuidValues)
+		ff.valueKind = valueKindUUID
+	default:
+		return fmt.Errorf("unsupported slice element type: %v", elemType)
+	}
+
 	for n := range v.Len() {
 		element := v.Index(n)
 
@@ -275,21 +413,9 @@ func (sg sliceGetter) getValue(ff *FilterField, v reflect.Value) error {
 		case reflect.String:
 			ff.appendStr(element.String())
 		case reflect.Struct:
-			if element.Type() == reflect.TypeOf(time.Time{}) {
-				value, err := timeValueToStr(element)
-				if err != nil {
-					return err
-				}
-				ff.appendStr(value)
-			}
+			ff.appendTime(element.Interface().(time.Time))
 		case reflect.Array:
-			if element.Type() == reflect.TypeOf(uuid.UUID{}) {
-				value, err := uuidValueToStr(element)
-				if err != nil {
-					return err
-				}
-				ff.appendStr(value)
-			}
+			ff.appendUUID(element.Interface().(uuid.UUID))
 		}
 	}
 	return nil
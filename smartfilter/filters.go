@@ -2,62 +2,208 @@ package smartfilter
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-func applyFilterEQ[T bool | int64 | uint64 | float64 | string](
+// filterValue lists value types which are bound to the driver as they are
+type filterValue interface {
+	bool | int64 | uint64 | float64 | string | time.Time | uuid.UUID
+}
+
+func applyFilterEQ[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s = ?", tableName, filterField.Name), value)
+	return query.Where(fmt.Sprintf("%s = ?", columnName(query, tableName, filterField)), value)
 }
 
-func applyFilterNE[T bool | int64 | uint64 | float64 | string](
+func applyFilterNE[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s != ?", tableName, filterField.Name), value)
+	return query.Where(fmt.Sprintf("%s != ?", columnName(query, tableName, filterField)), value)
 }
 
 func applyFilterLIKE(query *gorm.DB, tableName string, filterField *FilterField, value string) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s LIKE ?", tableName, filterField.Name), fmt.Sprintf("%%%s%%", value))
+	return query.Where(fmt.Sprintf("%s LIKE ?", columnName(query, tableName, filterField)), fmt.Sprintf("%%%s%%", value))
 }
 
 func applyFilterILIKE(query *gorm.DB, tableName string, filterField *FilterField, value string) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s ILIKE ?", tableName, filterField.Name), fmt.Sprintf("%%%s%%", value))
+	return query.Where(fmt.Sprintf("%s ILIKE ?", columnName(query, tableName, filterField)), fmt.Sprintf("%%%s%%", value))
 }
 
-func applyFilterGT[T bool | int64 | uint64 | float64 | string](
+func applyFilterGT[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s > ?", tableName, filterField.Name), value)
+	return query.Where(fmt.Sprintf("%s > ?", columnName(query, tableName, filterField)), value)
 }
 
-func applyFilterGE[T bool | int64 | uint64 | float64 | string](
+func applyFilterGE[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s >= ?", tableName, filterField.Name), value)
+	return query.Where(fmt.Sprintf("%s >= ?", columnName(query, tableName, filterField)), value)
 }
 
-func applyFilterLT[T bool | int64 | uint64 | float64 | string](
+func applyFilterLT[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s < ?", tableName, filterField.Name), value)
+	return query.Where(fmt.Sprintf("%s < ?", columnName(query, tableName, filterField)), value)
 }
 
-func applyFilterLE[T bool | int64 | uint64 | float64 | string](
+func applyFilterLE[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s <= ?", tableName, filterField.Name), value)
+	return query.Where(fmt.Sprintf("%s <= ?", columnName(query, tableName, filterField)), value)
 }
 
-func applyFilterIN[T bool | int64 | uint64 | float64 | string](
+func applyFilterIN[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value *[]T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s IN (?)", tableName, filterField.Name), *value)
+	// scalar filter fields have no values
+	if value == nil {
+		return nil
+	}
+	return query.Where(fmt.Sprintf("%s IN (?)", columnName(query, tableName, filterField)), *value)
 }
 
-func applyFilterNOT_IN[T bool | int64 | uint64 | float64 | string](
+func applyFilterNOT_IN[T filterValue](
 	query *gorm.DB, tableName string, filterField *FilterField, value *[]T,
 ) *gorm.DB {
-	return query.Where(fmt.Sprintf("%s.%s NOT IN (?)", tableName, filterField.Name), *value)
+	// scalar filter fields have no values
+	if value == nil {
+		return nil
+	}
+	return query.Where(fmt.Sprintf("%s NOT IN (?)", columnName(query, tableName, filterField)), *value)
+}
+
+func applyFilterBETWEEN[T filterValue](
+	query *gorm.DB, tableName string, filterField *FilterField, value *[]T,
+) *gorm.DB {
+	if value == nil || len(*value) != 2 {
+		return nil
+	}
+	return query.Where(fmt.Sprintf("%s BETWEEN ? AND ?", columnName(query, tableName, filterField)), (*value)[0], (*value)[1])
+}
+
+func applyFilterJSON_HAS_KEY(query *gorm.DB, tableName string, filterField *FilterField, value string) *gorm.DB {
+	column := columnName(query, tableName, filterField)
+	if dialectName(query) == dialectMySQL {
+		return query.Where(fmt.Sprintf(`JSON_CONTAINS_PATH(%s, 'one', CONCAT('$."', ?, '"'))`, column), value)
+	}
+	return query.Where(fmt.Sprintf("%s ? ?", column), sqlOperator("?"), value)
+}
+
+func applyFilterJSON_HAS_KEYS(
+	query *gorm.DB, tableName string, filterField *FilterField, value *[]string, all bool,
+) *gorm.DB {
+	column := columnName(query, tableName, filterField)
+	keys := *value
+
+	if dialectName(query) == dialectMySQL {
+		if len(keys) == 0 {
+			// match postgres semantics of empty key arrays
+			if all {
+				return query.Where("1 = 1")
+			}
+			return query.Where("1 = 0")
+		}
+		mode := "one"
+		if all {
+			mode = "all"
+		}
+		paths := strings.TrimSuffix(strings.Repeat(`CONCAT('$."', ?, '"'),`, len(keys)), ",")
+		vars := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			vars = append(vars, key)
+		}
+		return query.Where(fmt.Sprintf("JSON_CONTAINS_PATH(%s, '%s', %s)", column, mode, paths), vars...)
+	}
+
+	operator := "?|"
+	if all {
+		operator = "?&"
+	}
+	vars := make([]interface{}, 0, len(keys)+1)
+	vars = append(vars, sqlOperator(operator))
+	for _, key := range keys {
+		vars = append(vars, key)
+	}
+	return query.Where(fmt.Sprintf("%s ? ARRAY[%s]::text[]", column, placeholders(len(keys))), vars...)
+}
+
+func applyFilterJSON_CONTAINS(query *gorm.DB, tableName string, filterField *FilterField, document string) *gorm.DB {
+	column := columnName(query, tableName, filterField)
+	if dialectName(query) == dialectMySQL {
+		return query.Where(fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), document)
+	}
+	return query.Where(fmt.Sprintf("%s @> ?::jsonb", column), document)
+}
+
+// applyFilterArray compares postgres array column with given slice using
+// one of the array operators (@>, <@, &&)
+func applyFilterArray(
+	query *gorm.DB, tableName string, filterField *FilterField, operator string, values interface{},
+) *gorm.DB {
+	if dialectName(query) != dialectPostgres {
+		return WithError(query, fmt.Errorf("operator %s is supported only by postgres", filterField.Operator))
+	}
+	array, err := newPgArray(values)
+	if err != nil {
+		return nil
+	}
+	return query.Where(fmt.Sprintf("%s %s ?", columnName(query, tableName, filterField), operator), array)
+}
+
+func applyFilterANY[T filterValue](
+	query *gorm.DB, tableName string, filterField *FilterField, value T,
+) *gorm.DB {
+	if dialectName(query) != dialectPostgres {
+		return WithError(query, fmt.Errorf("operator %s is supported only by postgres", filterField.Operator))
+	}
+	return query.Where(fmt.Sprintf("? = ANY(%s)", columnName(query, tableName, filterField)), value)
+}
+
+func applyFilterSEARCH(query *gorm.DB, tableName string, filterField *FilterField, value string) *gorm.DB {
+	return query.Where(searchCondition(query, tableName, filterField, value))
+}
+
+// caseInsensitiveColumn returns column expression for case-insensitive
+// comparison. Citext columns compare case-insensitively on their own.
+func caseInsensitiveColumn(query *gorm.DB, tableName string, filterField *FilterField) string {
+	column := columnName(query, tableName, filterField)
+	if filterField.CIText {
+		return column
+	}
+	return fmt.Sprintf("LOWER(%s)", column)
+}
+
+func caseInsensitiveValue(filterField *FilterField, value string) string {
+	if filterField.CIText {
+		return value
+	}
+	return strings.ToLower(value)
+}
+
+func applyFilterIEQ(query *gorm.DB, tableName string, filterField *FilterField, value string) *gorm.DB {
+	return query.Where(
+		fmt.Sprintf("%s = ?", caseInsensitiveColumn(query, tableName, filterField)),
+		caseInsensitiveValue(filterField, value),
+	)
+}
+
+func applyFilterINE(query *gorm.DB, tableName string, filterField *FilterField, value string) *gorm.DB {
+	return query.Where(
+		fmt.Sprintf("%s != ?", caseInsensitiveColumn(query, tableName, filterField)),
+		caseInsensitiveValue(filterField, value),
+	)
+}
+
+func applyFilterIIN(query *gorm.DB, tableName string, filterField *FilterField, value *[]string) *gorm.DB {
+	values := make([]string, 0, len(*value))
+	for _, v := range *value {
+		values = append(values, caseInsensitiveValue(filterField, v))
+	}
+	return query.Where(fmt.Sprintf("%s IN (?)", caseInsensitiveColumn(query, tableName, filterField)), values)
 }
@@ -18,6 +18,10 @@ func handleOperatorEQ(query *gorm.DB, tableName string, filterField *FilterField
 		return applyFilterEQ(query, tableName, filterField, *filterField.floatValue)
 	case reflect.String:
 		return applyFilterEQ(query, tableName, filterField, *filterField.strValue)
+	case valueKindTime:
+		return applyFilterEQ(query, tableName, filterField, *filterField.timeValue)
+	case valueKindUUID:
+		return applyFilterEQ(query, tableName, filterField, *filterField.uuidValue)
 	}
 	return nil
 }
@@ -34,6 +38,10 @@ func handleOperatorNE(query *gorm.DB, tableName string, filterField *FilterField
 		return applyFilterNE(query, tableName, filterField, *filterField.floatValue)
 	case reflect.String:
 		return applyFilterNE(query, tableName, filterField, *filterField.strValue)
+	case valueKindTime:
+		return applyFilterNE(query, tableName, filterField, *filterField.timeValue)
+	case valueKindUUID:
+		return applyFilterNE(query, tableName, filterField, *filterField.uuidValue)
 	}
 	return nil
 }
@@ -64,6 +72,8 @@ func handleOperatorGT(query *gorm.DB, tableName string, filterField *FilterField
 		return applyFilterGT(query, tableName, filterField, *filterField.floatValue)
 	case reflect.String:
 		return applyFilterGT(query, tableName, filterField, *filterField.strValue)
+	case valueKindTime:
+		return applyFilterGT(query, tableName, filterField, *filterField.timeValue)
 	}
 	return nil
 }
@@ -78,6 +88,8 @@ func handleOperatorGE(query *gorm.DB, tableName string, filterField *FilterField
 		return applyFilterGE(query, tableName, filterField, *filterField.floatValue)
 	case reflect.String:
 		return applyFilterGE(query, tableName, filterField, *filterField.strValue)
+	case valueKindTime:
+		return applyFilterGE(query, tableName, filterField, *filterField.timeValue)
 	}
 	return nil
 }
@@ -92,6 +104,8 @@ func handleOperatorLT(query *gorm.DB, tableName string, filterField *FilterField
 		return applyFilterLT(query, tableName, filterField, *filterField.floatValue)
 	case reflect.String:
 		return applyFilterLT(query, tableName, filterField, *filterField.strValue)
+	case valueKindTime:
+		return applyFilterLT(query, tableName, filterField, *filterField.timeValue)
 	}
 	return nil
 }
@@ -106,6 +120,8 @@ func handleOperatorLE(query *gorm.DB, tableName string, filterField *FilterField
 		return applyFilterLE(query, tableName, filterField, *filterField.floatValue)
 	case reflect.String:
 		return applyFilterLE(query, tableName, filterField, *filterField.strValue)
+	case valueKindTime:
+		return applyFilterLE(query, tableName, filterField, *filterField.timeValue)
 	}
 	return nil
 }
@@ -122,6 +138,10 @@ func handleOperatorIN(query *gorm.DB, tableName string, filterField *FilterField
 		return applyFilterIN(query, tableName, filterField, filterField.floatValues)
 	case reflect.String:
 		return applyFilterIN(query, tableName, filterField, filterField.strValues)
+	case valueKindTime:
+		return applyFilterIN(query, tableName, filterField, filterField.timeValues)
+	case valueKindUUID:
+		return applyFilterIN(query, tableName, filterField, filterField.uuidValues)
 	}
 	return nil
 }
@@ -138,6 +158,148 @@ func handleOperatorNOT_IN(query *gorm.DB, tableName string, filterField *FilterF
 		return applyFilterNOT_IN(query, tableName, filterField, filterField.floatValues)
 	case reflect.String:
 		return applyFilterNOT_IN(query, tableName, filterField, filterField.strValues)
+	case valueKindTime:
+		return applyFilterNOT_IN(query, tableName, filterField, filterField.timeValues)
+	case valueKindUUID:
+		return applyFilterNOT_IN(query, tableName, filterField, filterField.uuidValues)
+	}
+	return nil
+}
+
+func handleOperatorBETWEEN(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+		return applyFilterBETWEEN(query, tableName, filterField, filterField.intValues)
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return applyFilterBETWEEN(query, tableName, filterField, filterField.uintValues)
+	case reflect.Float32, reflect.Float64:
+		return applyFilterBETWEEN(query, tableName, filterField, filterField.floatValues)
+	case reflect.String:
+		return applyFilterBETWEEN(query, tableName, filterField, filterField.strValues)
+	case valueKindTime:
+		return applyFilterBETWEEN(query, tableName, filterField, filterField.timeValues)
+	}
+	return nil
+}
+
+func handleOperatorJSON_HAS_KEY(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.String:
+		if filterField.strValue != nil {
+			return applyFilterJSON_HAS_KEY(query, tableName, filterField, *filterField.strValue)
+		}
+	}
+	return nil
+}
+
+func handleOperatorJSON_HAS_ANY(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.String:
+		if filterField.strValues != nil {
+			return applyFilterJSON_HAS_KEYS(query, tableName, filterField, filterField.strValues, false)
+		}
+	}
+	return nil
+}
+
+func handleOperatorJSON_HAS_ALL(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.String:
+		if filterField.strValues != nil {
+			return applyFilterJSON_HAS_KEYS(query, tableName, filterField, filterField.strValues, true)
+		}
+	}
+	return nil
+}
+
+func handleOperatorJSON_CONTAINS(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	document, err := filterField.jsonDocument()
+	if err != nil {
+		return nil
+	}
+	return applyFilterJSON_CONTAINS(query, tableName, filterField, document)
+}
+
+func handleOperatorCONTAINS(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	values := filterField.sliceValue()
+	if values == nil {
+		return nil
+	}
+	return applyFilterArray(query, tableName, filterField, "@>", values)
+}
+
+func handleOperatorCONTAINED_BY(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	values := filterField.sliceValue()
+	if values == nil {
+		return nil
+	}
+	return applyFilterArray(query, tableName, filterField, "<@", values)
+}
+
+func handleOperatorOVERLAPS(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	values := filterField.sliceValue()
+	if values == nil {
+		return nil
+	}
+	return applyFilterArray(query, tableName, filterField, "&&", values)
+}
+
+func handleOperatorANY(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch {
+	case filterField.boolValue != nil:
+		return applyFilterANY(query, tableName, filterField, *filterField.boolValue)
+	case filterField.intValue != nil:
+		return applyFilterANY(query, tableName, filterField, *filterField.intValue)
+	case filterField.uintValue != nil:
+		return applyFilterANY(query, tableName, filterField, *filterField.uintValue)
+	case filterField.floatValue != nil:
+		return applyFilterANY(query, tableName, filterField, *filterField.floatValue)
+	case filterField.strValue != nil:
+		return applyFilterANY(query, tableName, filterField, *filterField.strValue)
+	case filterField.timeValue != nil:
+		return applyFilterANY(query, tableName, filterField, *filterField.timeValue)
+	case filterField.uuidValue != nil:
+		return applyFilterANY(query, tableName, filterField, *filterField.uuidValue)
+	}
+	return nil
+}
+
+func handleOperatorSEARCH(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.String:
+		if filterField.strValue != nil {
+			return applyFilterSEARCH(query, tableName, filterField, *filterField.strValue)
+		}
+	}
+	return nil
+}
+
+func handleOperatorIEQ(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.String:
+		if filterField.strValue != nil {
+			return applyFilterIEQ(query, tableName, filterField, *filterField.strValue)
+		}
+	}
+	return nil
+}
+
+func handleOperatorINE(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.String:
+		if filterField.strValue != nil {
+			return applyFilterINE(query, tableName, filterField, *filterField.strValue)
+		}
+	}
+	return nil
+}
+
+func handleOperatorIIN(query *gorm.DB, tableName string, filterField *FilterField) *gorm.DB {
+	switch filterField.valueKind {
+	case reflect.String:
+		if filterField.strValues != nil {
+			return applyFilterIIN(query, tableName, filterField, filterField.strValues)
+		}
 	}
 	return nil
 }
@@ -4,9 +4,11 @@ import (
 	"log"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-playground/assert"
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -29,6 +31,38 @@ func NewMockDB() (*gorm.DB, sqlmock.Sqlmock) {
 	return gormDB, mock
 }
 
+// dialectOverride reports different dialect name, allowing dialect specific
+// SQL to be checked using postgres mock connection
+type dialectOverride struct {
+	gorm.Dialector
+	name string
+}
+
+func (d dialectOverride) Name() string {
+	return d.name
+}
+
+func NewMockDBWithDialect(name string) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	gormDB, err := gorm.Open(dialectOverride{
+		Dialector: postgres.New(postgres.Config{
+			WithoutQuotingCheck: true,
+			Conn:                db,
+		}),
+		name: name,
+	}, &gorm.Config{})
+
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening gorm database", err)
+	}
+
+	return gormDB, mock
+}
+
 type MyModel struct {
 	Id    int
 	Value string
@@ -51,12 +85,22 @@ var (
 	uint64Value uint64  = 123456
 	floatValue  float64 = -123456.789
 	strValue    string  = "Some Value"
+	timeValue           = time.Date(2024, 5, 26, 16, 8, 0, 123456789, time.UTC)
+	uuidValue           = uuid.MustParse("8d4a1e5c-5b5e-4f07-9d3b-3c7c2b8f1a11")
 
 	boolValues   = []bool{true, false}
 	int64Values  = []int64{-123456, 1, 123456}
 	uint64Values = []uint64{123456, 1234567, 1234568}
 	floatValues  = []float64{-123456.789, -1, 123456.789}
 	strValues    = []string{"First Value", "Second Value", "Third Value"}
+	timeValues   = []time.Time{
+		time.Date(2024, 5, 26, 16, 8, 0, 0, time.UTC),
+		time.Date(2024, 6, 26, 16, 8, 0, 0, time.UTC),
+	}
+	uuidValues = []uuid.UUID{
+		uuid.MustParse("8d4a1e5c-5b5e-4f07-9d3b-3c7c2b8f1a11"),
+		uuid.MustParse("0f8e4f1c-2f53-4bb4-a0a4-58d3a1b4c2de"),
+	}
 )
 
 func TestHandleOperatorEQ(t *testing.T) {
@@ -118,6 +162,24 @@ func TestHandleOperatorEQ(t *testing.T) {
 			},
 			expected: "SELECT * FROM my_models WHERE my_table.my_field = 'Some Value' ORDER BY my_models.id LIMIT 1",
 		},
+		{
+			name: "handleOperatorEQ time",
+			filterField: FilterField{
+				Name:      "my_field",
+				timeValue: &timeValue,
+				valueKind: valueKindTime,
+			},
+			expected: "SELECT * FROM my_models WHERE my_table.my_field = '2024-05-26 16:08:00.123' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name: "handleOperatorEQ uuid",
+			filterField: FilterField{
+				Name:      "my_field",
+				uuidValue: &uuidValue,
+				valueKind: valueKindUUID,
+			},
+			expected: "SELECT * FROM my_models WHERE my_table.my_field = '8d4a1e5c-5b5e-4f07-9d3b-3c7c2b8f1a11' ORDER BY my_models.id LIMIT 1",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -302,6 +364,15 @@ func TestHandleOperatorGT(t *testing.T) {
 			},
 			expected: "SELECT * FROM my_models WHERE my_table.my_field > 'Some Value' ORDER BY my_models.id LIMIT 1",
 		},
+		{
+			name: "handleOperatorGT time",
+			filterField: FilterField{
+				Name:      "my_field",
+				timeValue: &timeValue,
+				valueKind: valueKindTime,
+			},
+			expected: "SELECT * FROM my_models WHERE my_table.my_field > '2024-05-26 16:08:00.123' ORDER BY my_models.id LIMIT 1",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -531,6 +602,15 @@ func TestHandleOperatorIN(t *testing.T) {
 			},
 			expected: "SELECT * FROM my_models WHERE my_table.my_field IN ('First Value','Second Value','Third Value') ORDER BY my_models.id LIMIT 1",
 		},
+		{
+			name: "handleOperatorIN uuid",
+			filterField: FilterField{
+				Name:       "my_field",
+				uuidValues: &uuidValues,
+				valueKind:  valueKindUUID,
+			},
+			expected: "SELECT * FROM my_models WHERE my_table.my_field IN ('8d4a1e5c-5b5e-4f07-9d3b-3c7c2b8f1a11','0f8e4f1c-2f53-4bb4-a0a4-58d3a1b4c2de') ORDER BY my_models.id LIMIT 1",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -608,3 +688,368 @@ func TestHandleOperatorNOT_IN(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleOperatorBETWEEN(t *testing.T) {
+	db, _ := NewMockDB()
+	testFunc := handleOperatorBETWEEN
+
+	intBounds := []int64{10, 20}
+
+	testCases := []HandleOperatorTestCase{
+		{
+			name: "handleOperatorBETWEEN int64",
+			filterField: FilterField{
+				Name:      "my_field",
+				intValues: &intBounds,
+				valueKind: reflect.Int64,
+			},
+			expected: "SELECT * FROM my_models WHERE my_table.my_field BETWEEN 10 AND 20 ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name: "handleOperatorBETWEEN time",
+			filterField: FilterField{
+				Name:       "my_field",
+				timeValues: &timeValues,
+				valueKind:  valueKindTime,
+			},
+			expected: "SELECT * FROM my_models WHERE my_table.my_field BETWEEN '2024-05-26 16:08:00' AND '2024-06-26 16:08:00' ORDER BY my_models.id LIMIT 1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				query := tx.Model(&MyModel{})
+				query = testFunc(query, "my_table", &testCase.filterField)
+				return query.First(&MyModel{})
+			})
+			assert.Equal(t, testCase.expected, sql)
+		})
+	}
+
+	t.Run("handleOperatorBETWEEN invalid bounds", func(t *testing.T) {
+		filterField := FilterField{
+			Name:      "my_field",
+			strValues: &strValues,
+			valueKind: reflect.String,
+		}
+		query := testFunc(db, "my_table", &filterField)
+		assert.Equal(t, nil, query)
+	})
+}
+
+func TestHandleOperatorJSON(t *testing.T) {
+	type JSONTestCase struct {
+		name     string
+		dialect  string
+		handler  handlerFunc
+		field    string
+		value    interface{}
+		expected string
+	}
+
+	testCases := []JSONTestCase{
+		{
+			name:     "path equality",
+			dialect:  "postgres",
+			handler:  handleOperatorEQ,
+			field:    "attrs->>color",
+			value:    "red",
+			expected: "SELECT * FROM my_models WHERE my_table.attrs->>'color' = 'red' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "nested path equality",
+			dialect:  "postgres",
+			handler:  handleOperatorEQ,
+			field:    "attrs->meta->>color",
+			value:    "red",
+			expected: "SELECT * FROM my_models WHERE my_table.attrs->'meta'->>'color' = 'red' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "path equality mysql",
+			dialect:  "mysql",
+			handler:  handleOperatorEQ,
+			field:    "attrs->meta->>color",
+			value:    "red",
+			expected: "SELECT * FROM my_models WHERE JSON_UNQUOTE(JSON_EXTRACT(my_table.attrs, '$.meta.color')) = 'red' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "has key",
+			dialect:  "postgres",
+			handler:  handleOperatorJSON_HAS_KEY,
+			field:    "attrs",
+			value:    "color",
+			expected: "SELECT * FROM my_models WHERE my_table.attrs ? 'color' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "has key mysql",
+			dialect:  "mysql",
+			handler:  handleOperatorJSON_HAS_KEY,
+			field:    "attrs",
+			value:    "color",
+			expected: `SELECT * FROM my_models WHERE JSON_CONTAINS_PATH(my_table.attrs, 'one', CONCAT('$."', 'color', '"')) ORDER BY my_models.id LIMIT 1`,
+		},
+		{
+			name:     "has any key",
+			dialect:  "postgres",
+			handler:  handleOperatorJSON_HAS_ANY,
+			field:    "attrs",
+			value:    []string{"color", "size"},
+			expected: "SELECT * FROM my_models WHERE my_table.attrs ?| ARRAY['color','size']::text[] ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "has all keys",
+			dialect:  "postgres",
+			handler:  handleOperatorJSON_HAS_ALL,
+			field:    "attrs->meta",
+			value:    []string{"color", "size"},
+			expected: "SELECT * FROM my_models WHERE my_table.attrs->'meta' ?& ARRAY['color','size']::text[] ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "has all keys mysql",
+			dialect:  "mysql",
+			handler:  handleOperatorJSON_HAS_ALL,
+			field:    "attrs",
+			value:    []string{"color", "size"},
+			expected: `SELECT * FROM my_models WHERE JSON_CONTAINS_PATH(my_table.attrs, 'all', CONCAT('$."', 'color', '"'),CONCAT('$."', 'size', '"')) ORDER BY my_models.id LIMIT 1`,
+		},
+		{
+			name:     "contains map",
+			dialect:  "postgres",
+			handler:  handleOperatorJSON_CONTAINS,
+			field:    "attrs",
+			value:    map[string]interface{}{"color": "red"},
+			expected: `SELECT * FROM my_models WHERE my_table.attrs @> '{"color":"red"}'::jsonb ORDER BY my_models.id LIMIT 1`,
+		},
+		{
+			name:    "contains struct",
+			dialect: "postgres",
+			handler: handleOperatorJSON_CONTAINS,
+			field:   "attrs",
+			value: struct {
+				Size int `json:"size"`
+			}{Size: 42},
+			expected: `SELECT * FROM my_models WHERE my_table.attrs @> '{"size":42}'::jsonb ORDER BY my_models.id LIMIT 1`,
+		},
+		{
+			name:     "contains scalar",
+			dialect:  "postgres",
+			handler:  handleOperatorJSON_CONTAINS,
+			field:    "attrs->tags",
+			value:    "new",
+			expected: `SELECT * FROM my_models WHERE my_table.attrs->'tags' @> '"new"'::jsonb ORDER BY my_models.id LIMIT 1`,
+		},
+		{
+			name:     "contains mysql",
+			dialect:  "mysql",
+			handler:  handleOperatorJSON_CONTAINS,
+			field:    "attrs",
+			value:    map[string]interface{}{"color": "red"},
+			expected: `SELECT * FROM my_models WHERE JSON_CONTAINS(my_table.attrs, '{"color":"red"}') ORDER BY my_models.id LIMIT 1`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			db, _ := NewMockDBWithDialect(testCase.dialect)
+			filterField, err := newFilterField("field=" + testCase.field + ";operator=EQ")
+			assert.Equal(t, nil, err)
+			err = filterField.setValueFromReflection(reflect.ValueOf(testCase.value))
+			assert.Equal(t, nil, err)
+
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				query := tx.Model(&MyModel{})
+				query = testCase.handler(query, "my_table", filterField)
+				return query.First(&MyModel{})
+			})
+			assert.Equal(t, testCase.expected, sql)
+		})
+	}
+}
+
+func TestHandleArrayOperators(t *testing.T) {
+	db, _ := NewMockDB()
+
+	type ArrayTestCase struct {
+		name     string
+		handler  handlerFunc
+		value    interface{}
+		expected string
+	}
+
+	testCases := []ArrayTestCase{
+		{
+			name:     "contains strings",
+			handler:  handleOperatorCONTAINS,
+			value:    []string{"red", `say "hi"`},
+			expected: `SELECT * FROM my_models WHERE my_table.tags @> '{"red","say \"hi\""}' ORDER BY my_models.id LIMIT 1`,
+		},
+		{
+			name:     "contained by ints",
+			handler:  handleOperatorCONTAINED_BY,
+			value:    []int{1, 2, 3},
+			expected: "SELECT * FROM my_models WHERE my_table.tags <@ '{1,2,3}' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "overlaps uuids",
+			handler:  handleOperatorOVERLAPS,
+			value:    uuidValues,
+			expected: "SELECT * FROM my_models WHERE my_table.tags && '{8d4a1e5c-5b5e-4f07-9d3b-3c7c2b8f1a11,0f8e4f1c-2f53-4bb4-a0a4-58d3a1b4c2de}' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "overlaps empty",
+			handler:  handleOperatorOVERLAPS,
+			value:    []string{},
+			expected: "SELECT * FROM my_models WHERE my_table.tags && '{}' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "any",
+			handler:  handleOperatorANY,
+			value:    "admin",
+			expected: "SELECT * FROM my_models WHERE 'admin' = ANY(my_table.tags) ORDER BY my_models.id LIMIT 1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			filterField := FilterField{Name: "tags"}
+			err := filterField.setValueFromReflection(reflect.ValueOf(testCase.value))
+			assert.Equal(t, nil, err)
+
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				query := tx.Model(&MyModel{})
+				query = testCase.handler(query, "my_table", &filterField)
+				return query.First(&MyModel{})
+			})
+			assert.Equal(t, testCase.expected, sql)
+		})
+	}
+
+	t.Run("contains requires slice", func(t *testing.T) {
+		filterField := FilterField{Name: "tags", strValue: &strValue, valueKind: reflect.String}
+		query := handleOperatorCONTAINS(db, "my_table", &filterField)
+		assert.Equal(t, nil, query)
+	})
+
+	t.Run("unsupported dialect", func(t *testing.T) {
+		mysqlDB, _ := NewMockDBWithDialect("mysql")
+		filterField := FilterField{Name: "tags", Operator: OperatorCONTAINS, strValues: &strValues, valueKind: reflect.String}
+		query := handleOperatorCONTAINS(mysqlDB.Session(&gorm.Session{}), "my_table", &filterField)
+		assert.Equal(t, "operator CONTAINS is supported only by postgres", query.Error.Error())
+	})
+}
+
+func TestHandleOperatorSEARCH(t *testing.T) {
+	type SearchTestCase struct {
+		name     string
+		dialect  string
+		tag      string
+		expected string
+	}
+
+	testCases := []SearchTestCase{
+		{
+			name:     "postgres single column",
+			dialect:  "postgres",
+			tag:      "field=title;operator=SEARCH",
+			expected: "SELECT * FROM my_models WHERE to_tsvector('simple', my_table.title) @@ websearch_to_tsquery('simple', 'quick \"brown fox\"') ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "postgres multiple columns and config",
+			dialect:  "postgres",
+			tag:      "field=title,body;operator=SEARCH;config=english",
+			expected: "SELECT * FROM my_models WHERE to_tsvector('english', coalesce(my_table.title, '') || ' ' || coalesce(my_table.body, '')) @@ websearch_to_tsquery('english', 'quick \"brown fox\"') ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "mysql",
+			dialect:  "mysql",
+			tag:      "field=title,body;operator=SEARCH",
+			expected: "SELECT * FROM my_models WHERE MATCH (my_table.title, my_table.body) AGAINST ('quick \"brown fox\"' IN NATURAL LANGUAGE MODE) ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name:     "sqlite",
+			dialect:  "sqlite",
+			tag:      "field=title,body;operator=SEARCH",
+			expected: "SELECT * FROM my_models WHERE my_table MATCH '{title body} : \"quick\" \"\"\"brown\" \"fox\"\"\"' ORDER BY my_models.id LIMIT 1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			db, _ := NewMockDBWithDialect(testCase.dialect)
+			filterField, err := newFilterField(testCase.tag)
+			assert.Equal(t, nil, err)
+			err = filterField.setValueFromReflection(reflect.ValueOf(`quick "brown fox"`))
+			assert.Equal(t, nil, err)
+
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				query := tx.Model(&MyModel{})
+				query = handleOperatorSEARCH(query, "my_table", filterField)
+				return query.First(&MyModel{})
+			})
+			assert.Equal(t, testCase.expected, sql)
+		})
+	}
+}
+
+func TestHandleCaseInsensitiveOperators(t *testing.T) {
+	db, _ := NewMockDB()
+
+	mixedValue := "John.Doe@Example.com"
+	mixedValues := []string{"John", "JANE"}
+
+	testCases := []HandleOperatorTestCase{
+		{
+			name: "handleOperatorIEQ",
+			filterField: FilterField{
+				Name:      "email",
+				Operator:  OperatorIEQ,
+				strValue:  &mixedValue,
+				valueKind: reflect.String,
+			},
+			expected: "SELECT * FROM my_models WHERE LOWER(my_table.email) = 'john.doe@example.com' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name: "handleOperatorINE",
+			filterField: FilterField{
+				Name:      "email",
+				Operator:  OperatorINE,
+				strValue:  &mixedValue,
+				valueKind: reflect.String,
+			},
+			expected: "SELECT * FROM my_models WHERE LOWER(my_table.email) != 'john.doe@example.com' ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name: "handleOperatorIIN",
+			filterField: FilterField{
+				Name:      "username",
+				Operator:  OperatorIIN,
+				strValues: &mixedValues,
+				valueKind: reflect.String,
+			},
+			expected: "SELECT * FROM my_models WHERE LOWER(my_table.username) IN ('john','jane') ORDER BY my_models.id LIMIT 1",
+		},
+		{
+			name: "handleOperatorIEQ citext",
+			filterField: FilterField{
+				Name:      "email",
+				Operator:  OperatorIEQ,
+				CIText:    true,
+				strValue:  &mixedValue,
+				valueKind: reflect.String,
+			},
+			expected: "SELECT * FROM my_models WHERE my_table.email = 'John.Doe@Example.com' ORDER BY my_models.id LIMIT 1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				query := tx.Model(&MyModel{})
+				query = operatorHandlers[testCase.filterField.Operator](query, "my_table", &testCase.filterField)
+				return query.First(&MyModel{})
+			})
+			assert.Equal(t, testCase.expected, sql)
+		})
+	}
+}
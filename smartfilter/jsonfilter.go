@@ -0,0 +1,291 @@
+package smartfilter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FromJSON populates filter struct, passed as pointer, from flat json object.
+// Keys are named the same way as query parameters, e.g.
+// {"status__in": ["active", "pending"], "cnt__gt": 10}.
+func FromJSON(data []byte, filter interface{}) error {
+	return NewQueryStringBinder().BindJSON(data, filter)
+}
+
+// BindJSON populates filter struct, passed as pointer, from flat json object
+// using binder's naming. Keys not matching any filter field are ignored.
+func (b *QueryStringBinder) BindJSON(data []byte, filter interface{}) error {
+	v := reflect.ValueOf(filter)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("filter must be a pointer to struct, got %T", filter)
+	}
+	v = v.Elem()
+
+	fields, err := getTaggedFields(v.Type())
+	if err != nil {
+		return err
+	}
+	ordering, err := b.orderingParam(v.Type())
+	if err != nil {
+		return err
+	}
+
+	document := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	validationErrors := ValidationErrors{}
+	for _, tagged := range fields {
+		param := b.paramName(tagged)
+		raw, ok := document[param]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+
+		ptr := reflect.New(tagged.field.Type.Elem())
+		err := json.Unmarshal(raw, ptr.Interface())
+		if err != nil {
+			// strings may hold values in query string format, e.g. dates
+			// or separated lists
+			var str string
+			if json.Unmarshal(raw, &str) != nil {
+				err = fmt.Errorf("invalid value for type %v", tagged.field.Type.Elem())
+			} else if value, parseErr := b.parseParam(tagged.field.Type.Elem(), []string{str}); parseErr != nil {
+				err = parseErr
+			} else {
+				ptr.Elem().Set(value)
+				err = nil
+			}
+		}
+		if err != nil {
+			validationErrors = append(validationErrors, FieldError{
+				Param:   param,
+				Field:   tagged.field.Name,
+				Value:   string(raw),
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		v.FieldByIndex(tagged.field.Index).Set(ptr)
+	}
+
+	if ordering != nil {
+		if raw, ok := document[ordering.param]; ok && string(raw) != "null" {
+			var str string
+			if json.Unmarshal(raw, &str) != nil {
+				validationErrors = append(validationErrors, FieldError{
+					Param:   ordering.param,
+					Field:   ordering.field.Name,
+					Value:   string(raw),
+					Message: "ordering must be a string",
+				})
+			} else if fieldError := ordering.bind(v, str); fieldError != nil {
+				validationErrors = append(validationErrors, *fieldError)
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+// jsonCondition is a node of generic json filter document, either a field
+// condition or one of and/or/not groups:
+//
+//	{"and": [
+//		{"field": "status", "op": "IN", "value": ["active", "pending"]},
+//		{"not": {"field": "cnt", "op": "GT", "value": 5}}
+//	]}
+type jsonCondition struct {
+	Field *string          `json:"field"`
+	Op    string           `json:"op"`
+	Value json.RawMessage  `json:"value"`
+	And   []*jsonCondition `json:"and"`
+	Or    []*jsonCondition `json:"or"`
+	Not   *jsonCondition   `json:"not"`
+}
+
+// JSONFilter is a filter decoded from generic json filter document. It can
+// be passed to ToQuery and repository methods in place of filter struct.
+type JSONFilter struct {
+	condition *Condition
+}
+
+func (f *JSONFilter) filterCondition() (*Condition, error) {
+	return f.condition, nil
+}
+
+// ParseJSONFilter decodes generic json filter document. Only fields present
+// in allowlist may be used, they are translated to allowlisted columns.
+func ParseJSONFilter(data []byte, allowlist FieldAllowlist) (*JSONFilter, error) {
+	node := jsonCondition{}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	condition, err := node.toCondition(allowlist, "")
+	if err != nil {
+		return nil, err
+	}
+	return &JSONFilter{condition: condition}, nil
+}
+
+func jsonNodePath(path string, element string) string {
+	if len(path) == 0 {
+		return element
+	}
+	return fmt.Sprintf("%s.%s", path, element)
+}
+
+func (node *jsonCondition) toCondition(allowlist FieldAllowlist, path string) (*Condition, error) {
+	location := path
+	if len(location) == 0 {
+		location = "<root>"
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%s: empty condition", location)
+	}
+
+	kinds := 0
+	for _, set := range []bool{node.Field != nil, node.And != nil, node.Or != nil, node.Not != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return nil, fmt.Errorf("%s: condition must have exactly one of field, and, or, not", location)
+	}
+
+	switch {
+	case node.Field != nil:
+		condition, err := node.fieldCondition(allowlist)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", jsonNodePath(path, *node.Field), err)
+		}
+		return condition, nil
+	case node.Not != nil:
+		child, err := node.Not.toCondition(allowlist, jsonNodePath(path, "not"))
+		if err != nil {
+			return nil, err
+		}
+		return &Condition{Not: child}, nil
+	}
+
+	children, groupName := node.And, "and"
+	if node.Or != nil {
+		children, groupName = node.Or, "or"
+	}
+	conditions := make([]*Condition, 0, len(children))
+	for n, child := range children {
+		condition, err := child.toCondition(allowlist, jsonNodePath(path, fmt.Sprintf("%s[%d]", groupName, n)))
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	if groupName == "or" {
+		return &Condition{Or: conditions}, nil
+	}
+	return &Condition{And: conditions}, nil
+}
+
+func (node *jsonCondition) fieldCondition(allowlist FieldAllowlist) (*Condition, error) {
+	column, err := allowlist.resolve(*node.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := decodeJSONValue(node.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	filterField, err := newConditionField(column, Operator(strings.ToUpper(node.Op)), value)
+	if err != nil {
+		return nil, err
+	}
+	return &Condition{Field: filterField}, nil
+}
+
+// decodeJSONValue decodes condition value into types supported by filter
+// fields. Numbers become int64 or float64, arrays become typed slices.
+func decodeJSONValue(raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return normalizeJSONValue(value)
+}
+
+func normalizeJSONValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []interface{}:
+		return normalizeJSONArray(v)
+	}
+	return value, nil
+}
+
+func normalizeJSONArray(values []interface{}) (interface{}, error) {
+	items := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		item, err := normalizeJSONValue(value)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	var (
+		strs   []string
+		ints   []int64
+		floats []float64
+		bools  []bool
+	)
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			strs = append(strs, v)
+		case int64:
+			ints = append(ints, v)
+			floats = append(floats, float64(v))
+		case float64:
+			floats = append(floats, v)
+		case bool:
+			bools = append(bools, v)
+		default:
+			return nil, fmt.Errorf("unsupported array element: %v", item)
+		}
+	}
+
+	switch len(items) {
+	case 0:
+		return []string{}, nil
+	case len(strs):
+		return strs, nil
+	case len(ints):
+		return ints, nil
+	case len(floats):
+		return floats, nil
+	case len(bools):
+		return bools, nil
+	}
+	return nil, fmt.Errorf("array elements must be of the same type")
+}
@@ -0,0 +1,158 @@
+package smartfilter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestFromJSON(t *testing.T) {
+	t.Run("Populate filter", func(t *testing.T) {
+		id := uuid.New()
+		data := []byte(`{
+			"status": "active",
+			"status__in": ["active", "pending"],
+			"id__in": ["` + id.String() + `"],
+			"cnt__gt": 10,
+			"alive": true,
+			"created_at__ge": "2024-01-01",
+			"q": null,
+			"unrelated": {"a": 1}
+		}`)
+
+		filter := queryStringFilter{}
+		err := FromJSON(data, &filter)
+		assert.Nil(t, err)
+
+		assert.Equal(t, "active", *filter.Status)
+		assert.Equal(t, []string{"active", "pending"}, *filter.Statuses)
+		assert.Equal(t, []uuid.UUID{id}, *filter.Ids)
+		assert.Equal(t, 10, *filter.CntGT)
+		assert.True(t, *filter.Alive)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedGE)
+		assert.Nil(t, filter.Search)
+	})
+
+	t.Run("Report validation errors", func(t *testing.T) {
+		filter := queryStringFilter{}
+		err := FromJSON([]byte(`{"cnt__gt": "ten", "alive": 1}`), &filter)
+		assert.Equal(t, ValidationErrors{
+			{Param: "cnt__gt", Field: "CntGT", Value: `"ten"`, Message: "invalid integer value: ten"},
+			{Param: "alive", Field: "Alive", Value: "1", Message: "invalid value for type bool"},
+		}, err)
+	})
+
+	t.Run("Bind ordering", func(t *testing.T) {
+		filter := orderedFilter{}
+		err := FromJSON([]byte(`{"value": "x", "ordering": "-created,id"}`), &filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "x", *filter.Value)
+		assert.Equal(t, "-created,id", *filter.Order)
+
+		err = FromJSON([]byte(`{"ordering": ["id"]}`), &orderedFilter{})
+		assert.Equal(t, ValidationErrors{
+			{Param: "ordering", Field: "Order", Value: `["id"]`, Message: "ordering must be a string"},
+		}, err)
+	})
+}
+
+func TestParseJSONFilter(t *testing.T) {
+	allowlist := FieldAllowlist{
+		"status": "status",
+		"cnt":    "cnt",
+		"color":  "attrs->>color",
+	}
+
+	toSQL := func(filter interface{}) (string, error) {
+		db, _ := NewMockDB()
+		var err error
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			var query *gorm.DB
+			query, err = ToQuery(MyModel{}, filter, tx.Model(&MyModel{}))
+			if err != nil {
+				return tx
+			}
+			return query.Find(&[]MyModel{})
+		})
+		return sql, err
+	}
+
+	t.Run("Nested conditions", func(t *testing.T) {
+		filter, err := ParseJSONFilter([]byte(`{"and": [
+			{"field": "status", "op": "IN", "value": ["active", "pending"]},
+			{"or": [
+				{"field": "cnt", "op": "gt", "value": 5},
+				{"not": {"field": "color", "op": "EQ", "value": "red"}}
+			]}
+		]}`), allowlist)
+		assert.Nil(t, err)
+
+		sql, err := toSQL(filter)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"SELECT * FROM my_models WHERE my_models.status IN ('active','pending') AND (my_models.cnt > 5 OR NOT my_models.attrs->>'color' = 'red')",
+			sql,
+		)
+	})
+
+	t.Run("Single condition", func(t *testing.T) {
+		filter, err := ParseJSONFilter([]byte(`{"field": "cnt", "op": "BETWEEN", "value": [1, 2.5]}`), allowlist)
+		assert.Nil(t, err)
+
+		sql, err := toSQL(filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.cnt BETWEEN 1 AND 2.5", sql)
+	})
+
+	t.Run("Model allowlist", func(t *testing.T) {
+		modelAllowlist, err := ModelAllowlist(MyModel{})
+		assert.Nil(t, err)
+		assert.Equal(t, FieldAllowlist{"id": "id", "value": "value"}, modelAllowlist)
+
+		_, err = ParseJSONFilter([]byte(`{"field": "status", "op": "EQ", "value": "x"}`), modelAllowlist)
+		assert.EqualError(t, err, "status: field not allowed: status")
+	})
+
+	t.Run("Fail on field not allowed", func(t *testing.T) {
+		_, err := ParseJSONFilter([]byte(`{"or": [
+			{"field": "cnt", "op": "EQ", "value": 1},
+			{"field": "password", "op": "EQ", "value": "x"}
+		]}`), allowlist)
+		assert.EqualError(t, err, "or[1].password: field not allowed: password")
+	})
+
+	t.Run("Fail on unknown operator", func(t *testing.T) {
+		_, err := ParseJSONFilter([]byte(`{"not": {"field": "cnt", "op": "ROUGHLY", "value": 1}}`), allowlist)
+		assert.EqualError(t, err, "not.cnt: unknown operator: ROUGHLY")
+	})
+
+	t.Run("Fail on mixed array", func(t *testing.T) {
+		_, err := ParseJSONFilter([]byte(`{"field": "cnt", "op": "IN", "value": [1, "two"]}`), allowlist)
+		assert.EqualError(t, err, "cnt: array elements must be of the same type")
+	})
+
+	t.Run("Fail on ambiguous node", func(t *testing.T) {
+		_, err := ParseJSONFilter([]byte(`{"and": [{"field": "cnt", "op": "EQ", "value": 1, "or": []}]}`), allowlist)
+		assert.EqualError(t, err, "and[0]: condition must have exactly one of field, and, or, not")
+	})
+
+	t.Run("Fail on list value of single value operator", func(t *testing.T) {
+		_, err := ParseJSONFilter([]byte(`{"field": "status", "op": "EQ", "value": ["a"]}`), allowlist)
+		assert.EqualError(t, err, "status: status: operator EQ requires a single value")
+
+		_, err = ParseJSONFilter([]byte(`{"and": [{"field": "cnt", "op": "IN", "value": 1}]}`), allowlist)
+		assert.EqualError(t, err, "and[0].cnt: cnt: operator IN requires a list value")
+	})
+
+	t.Run("Fail on invalid value type for operator", func(t *testing.T) {
+		filter, err := ParseJSONFilter([]byte(`{"field": "status", "op": "LIKE", "value": 1}`), allowlist)
+		assert.Nil(t, err)
+
+		_, err = toSQL(filter)
+		assert.EqualError(t, err, "invalid field type for operator LIKE")
+	})
+}
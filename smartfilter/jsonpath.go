@@ -0,0 +1,93 @@
+package smartfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const JSON_PATH_SEPARATOR = "->"
+const JSON_PATH_TEXT_SEPARATOR = "->>"
+
+var jsonPathKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// jsonPath describes traversal into a json document column, as declared
+// in field name, e.g. "attrs->meta->>color".
+type jsonPath struct {
+	column string
+	keys   []string
+	// asText is set if the last key is extracted as text (->>)
+	asText bool
+}
+
+// parseJSONPath parses json path from field name. Nil is returned for plain
+// column names. Path keys are embedded into SQL, so only alphanumerics and
+// underscores are allowed.
+func parseJSONPath(name string) (*jsonPath, error) {
+	idx := strings.Index(name, JSON_PATH_SEPARATOR)
+	if idx < 0 {
+		return nil, nil
+	}
+
+	path := &jsonPath{column: strings.TrimSpace(name[:idx])}
+	rest := name[idx:]
+
+	for len(rest) > 0 {
+		var separator string
+		if strings.HasPrefix(rest, JSON_PATH_TEXT_SEPARATOR) {
+			separator = JSON_PATH_TEXT_SEPARATOR
+		} else if strings.HasPrefix(rest, JSON_PATH_SEPARATOR) {
+			separator = JSON_PATH_SEPARATOR
+		} else {
+			return nil, fmt.Errorf("invalid json path: %s", name)
+		}
+		if path.asText {
+			return nil, fmt.Errorf("text extraction must be the last json path element: %s", name)
+		}
+		rest = rest[len(separator):]
+
+		end := strings.Index(rest, JSON_PATH_SEPARATOR)
+		if end < 0 {
+			end = len(rest)
+		}
+		key := strings.Trim(strings.TrimSpace(rest[:end]), "'")
+		if !jsonPathKeyRegexp.MatchString(key) {
+			return nil, fmt.Errorf("invalid json path key: %s", key)
+		}
+
+		path.keys = append(path.keys, key)
+		path.asText = separator == JSON_PATH_TEXT_SEPARATOR
+		rest = rest[end:]
+	}
+
+	if len(path.column) == 0 {
+		return nil, fmt.Errorf("invalid json path: %s", name)
+	}
+	return path, nil
+}
+
+// expression renders json path traversal for column of given table.
+// Postgres and SQLite share the arrow operators, MySQL falls back to
+// JSON_EXTRACT.
+func (p *jsonPath) expression(dialect string, tableName string) string {
+	column := fmt.Sprintf("%s.%s", tableName, p.column)
+	if dialect == dialectMySQL {
+		expr := fmt.Sprintf("JSON_EXTRACT(%s, '$.%s')", column, strings.Join(p.keys, "."))
+		if p.asText {
+			expr = fmt.Sprintf("JSON_UNQUOTE(%s)", expr)
+		}
+		return expr
+	}
+
+	var sb strings.Builder
+	sb.WriteString(column)
+	for n, key := range p.keys {
+		if n == len(p.keys)-1 && p.asText {
+			sb.WriteString(JSON_PATH_TEXT_SEPARATOR)
+		} else {
+			sb.WriteString(JSON_PATH_SEPARATOR)
+		}
+		sb.WriteString(fmt.Sprintf("'%s'", key))
+	}
+	return sb.String()
+}
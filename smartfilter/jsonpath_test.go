@@ -0,0 +1,42 @@
+package smartfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	t.Run("Plain column name", func(t *testing.T) {
+		path, err := parseJSONPath("attrs")
+		assert.Nil(t, err)
+		assert.Nil(t, path)
+	})
+
+	t.Run("Text extraction", func(t *testing.T) {
+		path, err := parseJSONPath("attrs->>color")
+		assert.Nil(t, err)
+		assert.Equal(t, &jsonPath{column: "attrs", keys: []string{"color"}, asText: true}, path)
+	})
+
+	t.Run("Nested path", func(t *testing.T) {
+		path, err := parseJSONPath("attrs -> 'meta' ->> 'color'")
+		assert.Nil(t, err)
+		assert.Equal(t, &jsonPath{column: "attrs", keys: []string{"meta", "color"}, asText: true}, path)
+	})
+
+	t.Run("Fail on text extraction in the middle", func(t *testing.T) {
+		_, err := parseJSONPath("attrs->>meta->color")
+		assert.EqualError(t, err, "text extraction must be the last json path element: attrs->>meta->color")
+	})
+
+	t.Run("Fail on invalid key", func(t *testing.T) {
+		_, err := parseJSONPath("attrs->>col'); drop table x; --")
+		assert.EqualError(t, err, "invalid json path key: col'); drop table x; --")
+	})
+
+	t.Run("Fail on missing column", func(t *testing.T) {
+		_, err := parseJSONPath("->>color")
+		assert.EqualError(t, err, "invalid json path: ->>color")
+	})
+}
@@ -0,0 +1,470 @@
+package smartfilter
+
+import (
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/schema"
+)
+
+// matchResult is a result of SQL three-valued logic, comparisons with NULL
+// are unknown and row is matched only if the whole condition is true
+type matchResult int
+
+const (
+	matchFalse matchResult = iota
+	matchTrue
+	matchUnknown
+)
+
+func matchBool(b bool) matchResult {
+	if b {
+		return matchTrue
+	}
+	return matchFalse
+}
+
+func (r matchResult) not() matchResult {
+	switch r {
+	case matchTrue:
+		return matchFalse
+	case matchFalse:
+		return matchTrue
+	}
+	return matchUnknown
+}
+
+// operators which can be evaluated in memory
+var matchOperators = []Operator{
+	OperatorEQ,
+	OperatorNE,
+	OperatorGT,
+	OperatorGE,
+	OperatorLT,
+	OperatorLE,
+	OperatorLIKE,
+	OperatorILIKE,
+	OperatorIN,
+	OperatorNOT_IN,
+	OperatorBETWEEN,
+	OperatorIEQ,
+	OperatorINE,
+	OperatorIIN,
+}
+
+// columnValueFunc returns value of model column, nil for NULL
+type columnValueFunc func(column string) (interface{}, error)
+
+// Matches evaluates filter against model instance in Go, using the same
+// operator semantics as generated SQL, including NULL handling. Filter can
+// be tagged filter struct or runtime built filter. Supported operators are
+// EQ, NE, GT, GE, LT, LE, LIKE, ILIKE, IN, NOT_IN, BETWEEN, IEQ, INE and IIN.
+func Matches[T schema.Tabler](model T, filter interface{}) (bool, error) {
+	columnValue, err := modelColumnValues(model)
+	if err != nil {
+		return false, err
+	}
+
+	if conditionFilter, ok := filter.(conditionFilter); ok {
+		condition, err := conditionFilter.filterCondition()
+		if err != nil {
+			return false, err
+		}
+		result, err := condition.match(columnValue)
+		return result == matchTrue, err
+	}
+
+	if _, ok := filter.(QueryApplier); ok && !isNilPointer(filter) {
+		return false, fmt.Errorf("filters with custom queries can't be evaluated in memory")
+	}
+
+	filterFields, err := parseFilterFields(filter)
+	if err != nil {
+		return false, err
+	}
+	for _, filterField := range filterFields {
+		result, err := matchFilterField(filterField, columnValue)
+		if err != nil || result != matchTrue {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// ColumnValue returns value of model column, converted the same way as
+// for filter evaluation. Nil is returned for NULL.
+func ColumnValue[T schema.Tabler](model T, column string) (interface{}, error) {
+	columnValue, err := modelColumnValues(model)
+	if err != nil {
+		return nil, err
+	}
+	return columnValue(column)
+}
+
+// CompareValues compares two column values, as returned by ColumnValue.
+// NULL is greater than any other value, as in Postgres ordering.
+func CompareValues(a interface{}, b interface{}) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return 1, nil
+	case b == nil:
+		return -1, nil
+	}
+	return compareValues(a, b)
+}
+
+func modelColumnValues(model schema.Tabler) (columnValueFunc, error) {
+	modelSchema, err := parseModelSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	modelValue := reflect.ValueOf(model)
+	for modelValue.Kind() == reflect.Pointer {
+		if modelValue.IsNil() {
+			return nil, fmt.Errorf("model is nil")
+		}
+		modelValue = modelValue.Elem()
+	}
+
+	return func(column string) (interface{}, error) {
+		field := modelSchema.LookUpField(column)
+		if field == nil || len(field.DBName) == 0 {
+			return nil, fmt.Errorf("unknown column: %s", column)
+		}
+		value, _ := field.ValueOf(context.Background(), modelValue)
+		return normalizeModelValue(value)
+	}, nil
+}
+
+// normalizeModelValue converts model field value into one of filter value
+// types, or nil for NULL
+func normalizeModelValue(value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value, nil
+	case uuid.UUID:
+		return value, nil
+	case driver.Valuer:
+		// sql.Null* and other scanner types
+		dbValue, err := value.Value()
+		if err != nil {
+			return nil, err
+		}
+		if b, ok := dbValue.([]byte); ok {
+			return string(b), nil
+		}
+		return normalizeModelValue(dbValue)
+	}
+	return nil, fmt.Errorf("unsupported column type: %v", v.Type())
+}
+
+// match evaluates condition against model column values
+func (c *Condition) match(columnValue columnValueFunc) (matchResult, error) {
+	switch {
+	case c.Field != nil:
+		return matchFilterField(c.Field, columnValue)
+
+	case c.Not != nil:
+		result, err := c.Not.match(columnValue)
+		return result.not(), err
+
+	case len(c.And) > 0:
+		result := matchTrue
+		for _, child := range c.And {
+			childResult, err := child.match(columnValue)
+			if err != nil {
+				return matchFalse, err
+			}
+			if childResult == matchFalse {
+				return matchFalse, nil
+			}
+			if childResult == matchUnknown {
+				result = matchUnknown
+			}
+		}
+		return result, nil
+
+	case len(c.Or) > 0:
+		result := matchFalse
+		for _, child := range c.Or {
+			childResult, err := child.match(columnValue)
+			if err != nil {
+				return matchFalse, err
+			}
+			if childResult == matchTrue {
+				return matchTrue, nil
+			}
+			if childResult == matchUnknown {
+				result = matchUnknown
+			}
+		}
+		return result, nil
+	}
+	// empty condition matches everything
+	return matchTrue, nil
+}
+
+func matchFilterField(filterField *FilterField, columnValue columnValueFunc) (matchResult, error) {
+	if !slices.Contains(matchOperators, filterField.Operator) {
+		return matchFalse, fmt.Errorf("operator %s can't be evaluated in memory", filterField.Operator)
+	}
+	if filterField.jsonPath != nil {
+		return matchFalse, fmt.Errorf("json path fields can't be evaluated in memory: %s", filterField.Name)
+	}
+	if len(filterField.Table) > 0 {
+		return matchFalse, fmt.Errorf("joined table fields can't be evaluated in memory: %s.%s", filterField.Table, filterField.Name)
+	}
+	value, err := columnValue(filterField.Name)
+	if err != nil {
+		return matchFalse, err
+	}
+
+	scalar := filterField.scalarValue()
+	slice := reflect.ValueOf(filterField.sliceValue())
+	invalidType := fmt.Errorf("invalid field type for operator %s", filterField.Operator)
+	if filterField.valueKind == valueKindJSON {
+		return matchFalse, invalidType
+	}
+
+	switch filterField.Operator {
+	case OperatorEQ, OperatorNE, OperatorGT, OperatorGE, OperatorLT, OperatorLE:
+		if scalar == nil {
+			return matchFalse, invalidType
+		}
+		if value == nil {
+			return matchUnknown, nil
+		}
+		c, err := compareValues(value, scalar)
+		if err != nil {
+			return matchFalse, err
+		}
+		switch filterField.Operator {
+		case OperatorEQ:
+			return matchBool(c == 0), nil
+		case OperatorNE:
+			return matchBool(c != 0), nil
+		case OperatorGT:
+			return matchBool(c > 0), nil
+		case OperatorGE:
+			return matchBool(c >= 0), nil
+		case OperatorLT:
+			return matchBool(c < 0), nil
+		}
+		return matchBool(c <= 0), nil
+
+	case OperatorLIKE, OperatorILIKE, OperatorIEQ, OperatorINE:
+		pattern, ok := scalar.(string)
+		if !ok {
+			return matchFalse, invalidType
+		}
+		if value == nil {
+			return matchUnknown, nil
+		}
+		str, ok := value.(string)
+		if !ok {
+			return matchFalse, fmt.Errorf("cannot compare %T with string", value)
+		}
+		switch filterField.Operator {
+		case OperatorLIKE:
+			return matchBool(likeMatch(str, fmt.Sprintf("%%%s%%", pattern), false)), nil
+		case OperatorILIKE:
+			return matchBool(likeMatch(str, fmt.Sprintf("%%%s%%", pattern), true)), nil
+		case OperatorIEQ:
+			return matchBool(strings.EqualFold(str, pattern)), nil
+		}
+		return matchBool(!strings.EqualFold(str, pattern)), nil
+
+	case OperatorIN, OperatorNOT_IN, OperatorIIN:
+		if !slice.IsValid() || (filterField.Operator == OperatorIIN && filterField.valueKind != reflect.String) {
+			return matchFalse, invalidType
+		}
+		if value == nil {
+			return matchUnknown, nil
+		}
+		// empty list is rendered as IN (NULL)
+		result := matchUnknown
+		for i := 0; i < slice.Len(); i++ {
+			item := slice.Index(i).Interface()
+			if filterField.Operator == OperatorIIN {
+				str, ok := value.(string)
+				if !ok {
+					return matchFalse, fmt.Errorf("cannot compare %T with string", value)
+				}
+				if strings.EqualFold(str, item.(string)) {
+					result = matchTrue
+					break
+				}
+				result = matchFalse
+				continue
+			}
+			c, err := compareValues(value, item)
+			if err != nil {
+				return matchFalse, err
+			}
+			if c == 0 {
+				result = matchTrue
+				break
+			}
+			result = matchFalse
+		}
+		if filterField.Operator == OperatorNOT_IN {
+			return result.not(), nil
+		}
+		return result, nil
+
+	case OperatorBETWEEN:
+		if !slice.IsValid() || slice.Len() != 2 {
+			return matchFalse, invalidType
+		}
+		if value == nil {
+			return matchUnknown, nil
+		}
+		low, err := compareValues(value, slice.Index(0).Interface())
+		if err != nil {
+			return matchFalse, err
+		}
+		high, err := compareValues(value, slice.Index(1).Interface())
+		if err != nil {
+			return matchFalse, err
+		}
+		return matchBool(low >= 0 && high <= 0), nil
+	}
+	return matchFalse, invalidType
+}
+
+// compareValues compares model value with filter value. Numbers are
+// compared by value regardless of their type, uuids may be compared with
+// their string form.
+func compareValues(value interface{}, filterValue interface{}) (int, error) {
+	switch fv := filterValue.(type) {
+	case bool:
+		if v, ok := value.(bool); ok {
+			if v == fv {
+				return 0, nil
+			}
+			if !v {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case int64, uint64, float64:
+		if c, ok := compareNumbers(value, fv); ok {
+			return c, nil
+		}
+	case string:
+		switch v := value.(type) {
+		case string:
+			return strings.Compare(v, fv), nil
+		case uuid.UUID:
+			return strings.Compare(v.String(), strings.ToLower(fv)), nil
+		}
+	case time.Time:
+		if v, ok := value.(time.Time); ok {
+			return v.Compare(fv), nil
+		}
+	case uuid.UUID:
+		switch v := value.(type) {
+		case uuid.UUID:
+			return strings.Compare(v.String(), fv.String()), nil
+		case string:
+			return strings.Compare(strings.ToLower(v), fv.String()), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", value, filterValue)
+}
+
+func compareNumbers(a interface{}, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return cmp.Compare(a, b), true
+		case uint64:
+			if a < 0 || b > math.MaxInt64 {
+				return -1, true
+			}
+			return cmp.Compare(a, int64(b)), true
+		case float64:
+			return cmp.Compare(float64(a), b), true
+		}
+	case uint64:
+		switch b := b.(type) {
+		case int64:
+			c, ok := compareNumbers(b, a)
+			return -c, ok
+		case uint64:
+			return cmp.Compare(a, b), true
+		case float64:
+			return cmp.Compare(float64(a), b), true
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return cmp.Compare(a, float64(b)), true
+		case uint64:
+			return cmp.Compare(a, float64(b)), true
+		case float64:
+			return cmp.Compare(a, b), true
+		}
+	}
+	return 0, false
+}
+
+// likeMatch matches value against SQL LIKE pattern, % matches any sequence
+// of characters and _ matches a single character
+func likeMatch(value string, pattern string, caseInsensitive bool) bool {
+	var sb strings.Builder
+	sb.WriteString("(?s)")
+	if caseInsensitive {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String()).MatchString(value)
+}
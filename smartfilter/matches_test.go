@@ -0,0 +1,200 @@
+package smartfilter
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type matchModel struct {
+	Id        uuid.UUID
+	Name      string
+	Email     *string
+	Cnt       int
+	Price     float64
+	Alive     bool
+	CreatedAt time.Time
+	Note      sql.NullString
+}
+
+func (m matchModel) TableName() string {
+	return "match_models"
+}
+
+type matchFilter struct {
+	Id        *uuid.UUID   `filterfield:"field=id;operator=EQ"`
+	Names     *[]string    `filterfield:"field=name;operator=IN"`
+	NotNames  *[]string    `filterfield:"field=name;operator=NOT_IN"`
+	NameLike  *string      `filterfield:"field=name;operator=LIKE"`
+	NameILike *string      `filterfield:"field=name;operator=ILIKE"`
+	Email     *string      `filterfield:"field=email;operator=IEQ"`
+	EmailNE   *string      `filterfield:"field=email;operator=NE"`
+	CntGT     *int         `filterfield:"field=cnt;operator=GT"`
+	CntLE     *uint        `filterfield:"field=cnt;operator=LE"`
+	Price     *[]float64   `filterfield:"field=price;operator=BETWEEN"`
+	Alive     *bool        `filterfield:"field=alive;operator=EQ"`
+	Created   *time.Time   `filterfield:"field=created_at;operator=GE"`
+	Note      *string      `filterfield:"field=note;operator=EQ"`
+	Tags      *[]string    `filterfield:"field=tags;operator=CONTAINS"`
+	Unknown   *string      `filterfield:"field=unknown;operator=EQ"`
+	Ids       *[]uuid.UUID `filterfield:"field=id;operator=IN"`
+}
+
+func TestMatches(t *testing.T) {
+	id := uuid.New()
+	email := "John@Example.com"
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	model := matchModel{
+		Id:        id,
+		Name:      "50% off_sale",
+		Email:     &email,
+		Cnt:       10,
+		Price:     9.99,
+		Alive:     true,
+		CreatedAt: created,
+		Note:      sql.NullString{String: "note", Valid: true},
+	}
+
+	name := "50% off_sale"
+	other := "other"
+	like := "% off"
+	likeWildcard := "off_s"
+	ilike := "OFF_SALE"
+	lowerEmail := "john@example.com"
+	cnt := 9
+	cntMax := uint(10)
+	prices := []float64{9, 10}
+	alive := true
+	createdBefore := created.Add(-time.Nanosecond)
+	createdAfter := created.Add(time.Nanosecond)
+	note := "note"
+
+	cases := []struct {
+		name    string
+		filter  matchFilter
+		matches bool
+	}{
+		{"Empty filter", matchFilter{}, true},
+		{"Uuid equality", matchFilter{Id: &id}, true},
+		{"Uuid list", matchFilter{Ids: &[]uuid.UUID{uuid.New(), id}}, true},
+		{"In list", matchFilter{Names: &[]string{other, name}}, true},
+		{"Not in list", matchFilter{Names: &[]string{other}}, false},
+		{"Not in", matchFilter{NotNames: &[]string{other}}, true},
+		{"Empty in list", matchFilter{Names: &[]string{}}, false},
+		{"Empty not in list", matchFilter{NotNames: &[]string{}}, false},
+		{"Like", matchFilter{NameLike: &like}, true},
+		{"Like wildcard", matchFilter{NameLike: &likeWildcard}, true},
+		{"Like is case sensitive", matchFilter{NameLike: &ilike}, false},
+		{"ILike", matchFilter{NameILike: &ilike}, true},
+		{"Case insensitive equality", matchFilter{Email: &lowerEmail}, true},
+		{"Not equal is case sensitive", matchFilter{EmailNE: &lowerEmail}, true},
+		{"Greater than", matchFilter{CntGT: &cnt}, true},
+		{"Less or equal, mixed number types", matchFilter{CntLE: &cntMax}, true},
+		{"Between", matchFilter{Price: &prices}, true},
+		{"Bool", matchFilter{Alive: &alive}, true},
+		{"Time precision", matchFilter{Created: &createdBefore}, true},
+		{"Time precision, after", matchFilter{Created: &createdAfter}, false},
+		{"Null type value", matchFilter{Note: &note}, true},
+		{"All conditions", matchFilter{Id: &id, CntGT: &cnt, Alive: &alive, Names: &[]string{name}}, true},
+		{"One condition fails", matchFilter{Id: &id, CntGT: &cnt, Names: &[]string{other}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matches, err := Matches(model, c.filter)
+			assert.Nil(t, err)
+			assert.Equal(t, c.matches, matches)
+		})
+	}
+
+	t.Run("Null values", func(t *testing.T) {
+		nullModel := matchModel{Name: name}
+
+		for _, filter := range []matchFilter{
+			{Email: &lowerEmail},
+			{EmailNE: &lowerEmail},
+			{Note: &note},
+		} {
+			matches, err := Matches(&nullModel, filter)
+			assert.Nil(t, err)
+			assert.False(t, matches)
+		}
+
+		// NOT of unknown is unknown
+		matches, err := Matches(nullModel, New().Not("email", OperatorEQ, lowerEmail))
+		assert.Nil(t, err)
+		assert.False(t, matches)
+
+		// unknown OR true is true
+		matches, err = Matches(nullModel, New().Where("email", OperatorEQ, lowerEmail).Or("name", OperatorEQ, name))
+		assert.Nil(t, err)
+		assert.True(t, matches)
+	})
+
+	t.Run("Runtime filters", func(t *testing.T) {
+		filter, err := ParseExpression(
+			`cnt between 5 and 10 and (name ~ "OFF" or alive = false) and not id = `+uuid.NewString(),
+			FieldAllowlist{"cnt": "cnt", "name": "name", "alive": "alive", "id": "id"},
+		)
+		assert.Nil(t, err)
+
+		matches, err := Matches(model, filter)
+		assert.Nil(t, err)
+		assert.True(t, matches)
+	})
+
+	t.Run("Fail on unsupported filters", func(t *testing.T) {
+		tags := []string{"a"}
+		_, err := Matches(model, matchFilter{Tags: &tags})
+		assert.EqualError(t, err, "operator CONTAINS can't be evaluated in memory")
+
+		_, err = Matches(model, matchFilter{Unknown: &note})
+		assert.EqualError(t, err, "unknown column: unknown")
+
+		_, err = Matches(model, matchFilter{Note: &note, CntGT: &cnt, Alive: &alive, Id: &id, Price: &[]float64{1}})
+		assert.EqualError(t, err, "invalid field type for operator BETWEEN")
+
+		_, err = Matches(model, New().Where("cnt", OperatorEQ, "ten"))
+		assert.EqualError(t, err, "cannot compare int64 with string")
+	})
+}
+
+func TestCompareValues(t *testing.T) {
+	id := uuid.New()
+	model := matchModel{Id: id, Cnt: 3}
+
+	value, err := ColumnValue(model, "cnt")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), value)
+
+	value, err = ColumnValue(&model, "Id")
+	assert.Nil(t, err)
+	assert.Equal(t, id, value)
+
+	value, err = ColumnValue(model, "email")
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	_, err = ColumnValue(model, "unknown")
+	assert.EqualError(t, err, "unknown column: unknown")
+
+	for _, c := range []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{int64(1), float64(1.5), -1},
+		{"b", "a", 1},
+		{nil, int64(1), 1},
+		{int64(1), nil, -1},
+		{nil, nil, 0},
+	} {
+		result, err := CompareValues(c.a, c.b)
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, result)
+	}
+
+	_, err = CompareValues("a", int64(1))
+	assert.EqualError(t, err, "cannot compare string with int64")
+}
@@ -3,21 +3,53 @@ package smartfilter
 type Operator string
 
 const (
-	OperatorEQ     Operator = "EQ"
-	OperatorNE     Operator = "NE"
-	OperatorGT     Operator = "GT"
-	OperatorGE     Operator = "GE"
-	OperatorLT     Operator = "LT"
-	OperatorLE     Operator = "LE"
-	OperatorLIKE   Operator = "LIKE"
-	OperatorILIKE  Operator = "ILIKE"
-	OperatorIN     Operator = "IN"
-	OperatorNOT_IN Operator = "NOT_IN"
+	OperatorEQ      Operator = "EQ"
+	OperatorNE      Operator = "NE"
+	OperatorGT      Operator = "GT"
+	OperatorGE      Operator = "GE"
+	OperatorLT      Operator = "LT"
+	OperatorLE      Operator = "LE"
+	OperatorLIKE    Operator = "LIKE"
+	OperatorILIKE   Operator = "ILIKE"
+	OperatorIN      Operator = "IN"
+	OperatorNOT_IN  Operator = "NOT_IN"
+	OperatorBETWEEN Operator = "BETWEEN"
+
+	OperatorJSON_HAS_KEY  Operator = "JSON_HAS_KEY"
+	OperatorJSON_HAS_ANY  Operator = "JSON_HAS_ANY"
+	OperatorJSON_HAS_ALL  Operator = "JSON_HAS_ALL"
+	OperatorJSON_CONTAINS Operator = "JSON_CONTAINS"
+
+	OperatorCONTAINS     Operator = "CONTAINS"
+	OperatorCONTAINED_BY Operator = "CONTAINED_BY"
+	OperatorOVERLAPS     Operator = "OVERLAPS"
+	OperatorANY          Operator = "ANY"
+
+	OperatorSEARCH Operator = "SEARCH"
+
+	OperatorIEQ Operator = "IEQ"
+	OperatorINE Operator = "INE"
+	OperatorIIN Operator = "IIN"
 )
 
+// operators taking a list of values. JSON_CONTAINS takes any json document,
+// other operators take a single value.
+var listOperators = []Operator{
+	OperatorIN, OperatorNOT_IN,
+	OperatorBETWEEN,
+	OperatorJSON_HAS_ANY, OperatorJSON_HAS_ALL,
+	OperatorCONTAINS, OperatorCONTAINED_BY, OperatorOVERLAPS,
+	OperatorIIN,
+}
+
 var OPERATORS = []Operator{
 	OperatorEQ, OperatorNE,
 	OperatorGT, OperatorGE, OperatorLT, OperatorLE,
 	OperatorLIKE, OperatorILIKE,
 	OperatorIN, OperatorNOT_IN,
+	OperatorBETWEEN,
+	OperatorJSON_HAS_KEY, OperatorJSON_HAS_ANY, OperatorJSON_HAS_ALL, OperatorJSON_CONTAINS,
+	OperatorCONTAINS, OperatorCONTAINED_BY, OperatorOVERLAPS, OperatorANY,
+	OperatorSEARCH,
+	OperatorIEQ, OperatorINE, OperatorIIN,
 }
@@ -0,0 +1,276 @@
+package smartfilter
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const ORDER_TAG_NAME = "filterorder"
+const ORDERING_SEPARATOR = ","
+const ORDERING_DIRECTION_SEPARATOR = ":"
+
+// table and column names are embedded into SQL, only plain identifiers are
+// allowed
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsIdentifier reports whether name is a plain identifier, safe to be
+// embedded into SQL as table, column or alias name
+func IsIdentifier(name string) bool {
+	return identifierRegexp.MatchString(name)
+}
+
+// orderByList is a comma separated list of ordering expressions. Gorm
+// keeps merging column ordering into the clause, absorbedColumns tracks how
+// many of those columns are already part of the list.
+type orderByList struct {
+	expressions     []clause.Expression
+	absorbedColumns int
+}
+
+func (list orderByList) Build(builder clause.Builder) {
+	for idx, expression := range list.expressions {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		expression.Build(builder)
+	}
+}
+
+func currentOrderBy(query *gorm.DB) clause.OrderBy {
+	if c, ok := query.Statement.Clauses["ORDER BY"]; ok {
+		if orderBy, ok := c.Expression.(clause.OrderBy); ok {
+			return orderBy
+		}
+	}
+	return clause.OrderBy{}
+}
+
+// AppendOrder appends ordering expression to query. Gorm replaces column
+// ordering with expression ordering once an expression with bind variables
+// is used, so all ordering is then merged into a single expression list.
+func AppendOrder(query *gorm.DB, expression clause.Expression) *gorm.DB {
+	orderBy := currentOrderBy(query)
+
+	if expr, ok := expression.(clause.Expr); ok && len(expr.Vars) == 0 && orderBy.Expression == nil {
+		// plain ordering, keep column form so gorm merges it as usual
+		return query.Order(clause.OrderByColumn{Column: clause.Column{Name: expr.SQL, Raw: true}})
+	}
+
+	list := orderByList{}
+	columns := orderBy.Columns
+	if existing, ok := orderBy.Expression.(orderByList); ok {
+		list.expressions = append(list.expressions, existing.expressions...)
+		columns = columns[existing.absorbedColumns:]
+	} else if orderBy.Expression != nil {
+		list.expressions = append(list.expressions, orderBy.Expression)
+	}
+	for _, column := range columns {
+		sql := "?"
+		if column.Desc {
+			sql = "? DESC"
+		}
+		list.expressions = append(
+			list.expressions,
+			clause.Expr{SQL: sql, Vars: []interface{}{column.Column}, WithoutParentheses: true},
+		)
+	}
+	list.expressions = append(list.expressions, expression)
+	list.absorbedColumns = len(orderBy.Columns)
+
+	return query.Clauses(clause.OrderBy{Expression: list})
+}
+
+// OrderColumn is a single item of parsed ordering. Table is set for
+// allowlisted columns qualified with table name, e.g. "customers.name".
+type OrderColumn struct {
+	Table string
+	Field string
+	Desc  bool
+}
+
+// OrderingError describes invalid item of ordering string
+type OrderingError struct {
+	Value   string
+	Message string
+}
+
+func (e OrderingError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Value, e.Message)
+}
+
+// OrderingErrors holds all errors found while parsing ordering string
+type OrderingErrors []OrderingError
+
+func (e OrderingErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, orderingError := range e {
+		messages = append(messages, orderingError.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ParseOrdering parses user provided ordering string, e.g.
+// "-created_at,name". Fields are ordered ascending, unless prefixed with
+// "-", direction may also be given as suffix, e.g. "name:desc". Only fields
+// present in allowlist may be used, they are translated to allowlisted
+// columns. Invalid items are reported as OrderingErrors.
+func ParseOrdering(ordering string, allowlist FieldAllowlist) ([]OrderColumn, error) {
+	columns := make([]OrderColumn, 0)
+	orderingErrors := OrderingErrors{}
+	seen := map[OrderColumn]bool{}
+
+	for _, item := range strings.Split(ordering, ORDERING_SEPARATOR) {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+
+		column, err := parseOrderingItem(item, allowlist)
+		if err != nil {
+			orderingErrors = append(orderingErrors, OrderingError{Value: item, Message: err.Error()})
+			continue
+		}
+		key := OrderColumn{Table: column.Table, Field: column.Field}
+		if seen[key] {
+			orderingErrors = append(orderingErrors, OrderingError{Value: item, Message: "duplicate ordering field"})
+			continue
+		}
+		seen[key] = true
+		columns = append(columns, *column)
+	}
+
+	if len(orderingErrors) > 0 {
+		return nil, orderingErrors
+	}
+	return columns, nil
+}
+
+func parseOrderingItem(item string, allowlist FieldAllowlist) (*OrderColumn, error) {
+	name := item
+	desc := false
+	prefixed := false
+
+	switch name[0] {
+	case '-':
+		desc = true
+		prefixed = true
+		name = name[1:]
+	case '+':
+		prefixed = true
+		name = name[1:]
+	}
+
+	if idx := strings.Index(name, ORDERING_DIRECTION_SEPARATOR); idx >= 0 {
+		if prefixed {
+			return nil, fmt.Errorf("direction given both as prefix and suffix")
+		}
+		switch strings.ToUpper(strings.TrimSpace(name[idx+1:])) {
+		case "ASC":
+		case "DESC":
+			desc = true
+		default:
+			return nil, fmt.Errorf("invalid direction: %s", name[idx+1:])
+		}
+		name = name[:idx]
+	}
+
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return nil, fmt.Errorf("missing field name")
+	}
+	columnName, err := allowlist.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// allowlisted columns may be qualified with table name
+	column := OrderColumn{Field: columnName, Desc: desc}
+	if table, field, ok := strings.Cut(columnName, "."); ok {
+		column.Table, column.Field = table, field
+	}
+	if !IsIdentifier(column.Field) || (len(column.Table) > 0 && !IsIdentifier(column.Table)) {
+		return nil, fmt.Errorf("column can't be used for ordering: %s", columnName)
+	}
+	return &column, nil
+}
+
+// parseOrderAllowlist parses filterorder tag value, a list of allowed sort
+// keys. Keys are mapped to columns of the same name, unless given as
+// "key=column", e.g. "name,created=created_at,customer=customers.name".
+func parseOrderAllowlist(tagValue string) (FieldAllowlist, error) {
+	allowlist := FieldAllowlist{}
+	for _, item := range splitTrim(tagValue, TAG_LIST_SEPARATOR) {
+		key, column, found := strings.Cut(item, TAG_KEYVALUE_SEPARATOR)
+		key, column = strings.TrimSpace(key), strings.TrimSpace(column)
+		if !found {
+			column = key
+		}
+		if len(key) == 0 || len(column) == 0 {
+			return nil, fmt.Errorf("invalid ordering key: %s", item)
+		}
+		allowlist[key] = column
+	}
+	if len(allowlist) == 0 {
+		return nil, fmt.Errorf("missing ordering keys in tag")
+	}
+	return allowlist, nil
+}
+
+// orderingField returns field of filter struct type tagged with allowed
+// sort keys and its allowlist, nil if filter has no ordering field
+func orderingField(st reflect.Type) (*reflect.StructField, FieldAllowlist, error) {
+	var (
+		found     *reflect.StructField
+		allowlist FieldAllowlist
+	)
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		tagValue := field.Tag.Get(ORDER_TAG_NAME)
+		if len(tagValue) == 0 {
+			continue
+		}
+		if found != nil {
+			return nil, nil, fmt.Errorf("%s.%s: only one ordering field is allowed", st.Name(), field.Name)
+		}
+
+		if field.Type != reflect.TypeOf((*string)(nil)) {
+			return nil, nil, fmt.Errorf("%s.%s: ordering field must be *string", st.Name(), field.Name)
+		}
+		var err error
+		allowlist, err = parseOrderAllowlist(tagValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %s", st.Name(), field.Name, err)
+		}
+		found = &field
+	}
+	return found, allowlist, nil
+}
+
+// FilterOrdering parses ordering of filter struct. Ordering is given by
+// a *string field tagged with allowed sort keys, e.g.
+//
+//	Order *string `filterorder:"name,created=created_at"`
+//
+// Nil is returned if filter has no ordering field or its value is nil.
+// Invalid ordering values are reported as OrderingErrors.
+func FilterOrdering(filter interface{}) ([]OrderColumn, error) {
+	reflectValue, err := indirectFilter(filter)
+	if err != nil || !reflectValue.IsValid() {
+		return nil, err
+	}
+
+	field, allowlist, err := orderingField(reflectValue.Type())
+	if err != nil || field == nil {
+		return nil, err
+	}
+	fieldValue := reflectValue.FieldByIndex(field.Index)
+	if fieldValue.IsNil() {
+		return nil, nil
+	}
+	return ParseOrdering(*fieldValue.Interface().(*string), allowlist)
+}
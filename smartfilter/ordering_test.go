@@ -0,0 +1,90 @@
+package smartfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestAppendOrder(t *testing.T) {
+	db, _ := NewMockDB()
+
+	t.Run("Plain ordering keeps column form", func(t *testing.T) {
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			query := AppendOrder(tx.Model(&MyModel{}), clause.Expr{SQL: "value"})
+			query = AppendOrder(query, clause.Expr{SQL: "id DESC"})
+			return query.First(&MyModel{})
+		})
+		assert.Equal(t, "SELECT * FROM my_models ORDER BY value,id DESC,my_models.id LIMIT 1", sql)
+	})
+
+	t.Run("Expression merged with existing ordering", func(t *testing.T) {
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			query := tx.Model(&MyModel{}).Order("value")
+			query = AppendOrder(query, clause.Expr{SQL: "id = ? DESC", Vars: []interface{}{1}, WithoutParentheses: true})
+			query = AppendOrder(query, clause.Expr{SQL: "id"})
+			return query.Find(&[]MyModel{})
+		})
+		assert.Equal(t, "SELECT * FROM my_models ORDER BY value,id = 1 DESC,id", sql)
+	})
+}
+
+type orderedFilter struct {
+	Value *string `filterfield:"field=value;operator=EQ"`
+	Order *string `filterorder:"id,value,created=created_at,customer=customers.name"`
+}
+
+func TestFilterOrdering(t *testing.T) {
+	order := func(value string) *string {
+		return &value
+	}
+
+	t.Run("Parse ordering field", func(t *testing.T) {
+		ordering, err := FilterOrdering(&orderedFilter{Order: order("-created,customer:asc,value")})
+		assert.Nil(t, err)
+		assert.Equal(t, []OrderColumn{
+			{Field: "created_at", Desc: true},
+			{Table: "customers", Field: "name"},
+			{Field: "value"},
+		}, ordering)
+
+		ordering, err = FilterOrdering(orderedFilter{})
+		assert.Nil(t, err)
+		assert.Nil(t, ordering)
+
+		ordering, err = FilterOrdering(MyModel{})
+		assert.Nil(t, err)
+		assert.Nil(t, ordering)
+	})
+
+	t.Run("Fail on invalid ordering", func(t *testing.T) {
+		_, err := FilterOrdering(orderedFilter{Order: order("password,id:up")})
+		assert.Equal(t, OrderingErrors{
+			{Value: "password", Message: "field not allowed: password"},
+			{Value: "id:up", Message: "invalid direction: up"},
+		}, err)
+	})
+
+	t.Run("Fail on invalid ordering field", func(t *testing.T) {
+		type valueOrder struct {
+			Order string `filterorder:"id"`
+		}
+		_, err := FilterOrdering(valueOrder{})
+		assert.EqualError(t, err, "valueOrder.Order: ordering field must be *string")
+
+		type emptyKey struct {
+			Order *string `filterorder:"id,=value"`
+		}
+		_, err = FilterOrdering(emptyKey{})
+		assert.EqualError(t, err, "emptyKey.Order: invalid ordering key: =value")
+
+		type twoOrders struct {
+			Order  *string `filterorder:"id"`
+			Order2 *string `filterorder:"value"`
+		}
+		_, err = FilterOrdering(twoOrders{})
+		assert.EqualError(t, err, "twoOrders.Order2: only one ordering field is allowed")
+	})
+}
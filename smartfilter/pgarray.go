@@ -0,0 +1,66 @@
+package smartfilter
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pgArray binds a slice as a single postgres array literal. Contrary to
+// ARRAY[...] constructor, postgres infers parameter type from the compared
+// column, so the same slice works for text[], varchar[], uuid[] or int[].
+type pgArray struct {
+	elements []string
+}
+
+func newPgArray(values interface{}) (*pgArray, error) {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("expected slice, got %v", v.Type())
+	}
+
+	array := pgArray{elements: make([]string, 0, v.Len())}
+	for n := range v.Len() {
+		element, err := pgArrayElement(v.Index(n).Interface())
+		if err != nil {
+			return nil, err
+		}
+		array.elements = append(array.elements, element)
+	}
+	return &array, nil
+}
+
+func pgArrayElement(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return pgArrayQuote(v), nil
+	case time.Time:
+		return pgArrayQuote(v.Format(time.RFC3339Nano)), nil
+	case uuid.UUID:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("unsupported array element type: %T", value)
+}
+
+func pgArrayQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return fmt.Sprintf(`"%s"`, value)
+}
+
+func (a pgArray) Value() (driver.Value, error) {
+	return fmt.Sprintf("{%s}", strings.Join(a.elements, ",")), nil
+}
@@ -0,0 +1,192 @@
+package smartfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// operators for which order of list values is irrelevant, their values
+// are sorted in canonical form
+var unorderedListOperators = []Operator{
+	OperatorIN,
+	OperatorNOT_IN,
+	OperatorIIN,
+	OperatorJSON_HAS_ANY,
+	OperatorJSON_HAS_ALL,
+	OperatorCONTAINS,
+	OperatorCONTAINED_BY,
+	OperatorOVERLAPS,
+}
+
+// ToURLValues serializes filter struct into query parameters using default
+// naming scheme. Result can be bound back with FromQueryString.
+func ToURLValues(filter interface{}) (url.Values, error) {
+	return NewQueryStringBinder().Encode(filter)
+}
+
+// ToQueryString serializes filter struct into encoded query string
+func ToQueryString(filter interface{}) (string, error) {
+	values, err := ToURLValues(filter)
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// CanonicalString serializes filter struct into stable string, suitable as
+// a cache key. Equal filters produce the same string.
+func CanonicalString(filter interface{}) (string, error) {
+	return NewQueryStringBinder().Canonical(filter)
+}
+
+// Encode serializes filter struct, passed as value or pointer, into query
+// parameters using binder's naming. Nil fields are omitted, lists are joined
+// with list separator.
+func (b *QueryStringBinder) Encode(filter interface{}) (url.Values, error) {
+	return b.encode(filter, false)
+}
+
+// Canonical serializes filter struct into stable string using binder's
+// naming. Parameters are sorted by name, unordered lists are sorted and
+// times are converted to UTC.
+func (b *QueryStringBinder) Canonical(filter interface{}) (string, error) {
+	values, err := b.encode(filter, true)
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+func (b *QueryStringBinder) encode(filter interface{}, canonical bool) (url.Values, error) {
+	v := reflect.ValueOf(filter)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("filter must be a struct or pointer to struct, got %T", filter)
+	}
+
+	fields, err := getTaggedFields(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	ordering, err := b.orderingParam(v.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	// fields sharing parameter name can't be bound back
+	params := map[string]bool{}
+	if ordering != nil {
+		params[ordering.param] = true
+	}
+	for _, tagged := range fields {
+		param := b.paramName(tagged)
+		if params[param] {
+			return nil, fmt.Errorf("duplicate query parameter: %s", param)
+		}
+		params[param] = true
+	}
+
+	values := url.Values{}
+	for _, tagged := range fields {
+		fieldValue := v.FieldByIndex(tagged.field.Index)
+		if fieldValue.IsNil() {
+			continue
+		}
+		param := b.paramName(tagged)
+
+		raw, err := b.formatParam(fieldValue.Elem(), tagged.filterField, canonical)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", param, err)
+		}
+		values.Set(param, raw)
+	}
+
+	if ordering != nil {
+		if fieldValue := v.FieldByIndex(ordering.field.Index); !fieldValue.IsNil() {
+			values.Set(ordering.param, *fieldValue.Interface().(*string))
+		}
+	}
+	return values, nil
+}
+
+// formatParam formats filter value as query parameter value, the inverse
+// of parseParam
+func (b *QueryStringBinder) formatParam(value reflect.Value, filterField *FilterField, canonical bool) (string, error) {
+	if value.Kind() != reflect.Slice {
+		return formatStringValue(value, canonical)
+	}
+
+	separator := b.listSeparator()
+	items := make([]string, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		item, err := formatStringValue(value.Index(i), canonical)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(item, separator) {
+			return "", fmt.Errorf("list value contains separator: %s", item)
+		}
+		items = append(items, item)
+	}
+
+	if canonical && slices.Contains(unorderedListOperators, filterField.Operator) {
+		slices.Sort(items)
+		items = slices.Compact(items)
+	}
+	return strings.Join(items, separator), nil
+}
+
+// formatStringValue formats filter value in textual representation accepted
+// by parseStringValue
+func formatStringValue(value reflect.Value, canonical bool) (string, error) {
+	switch value.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()), nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Map:
+		return formatJSONValue(value)
+	case reflect.Struct:
+		tm, ok := value.Interface().(time.Time)
+		if !ok {
+			return formatJSONValue(value)
+		}
+		if canonical {
+			tm = tm.UTC()
+		}
+		return tm.Format(time.RFC3339Nano), nil
+	case reflect.Array:
+		u, ok := value.Interface().(uuid.UUID)
+		if !ok {
+			return "", fmt.Errorf("unsupported type: %v", value.Type())
+		}
+		return u.String(), nil
+	}
+	return "", fmt.Errorf("unsupported type: %v", value.Type())
+}
+
+// formatJSONValue formats map or struct value as json document, map keys
+// are sorted so the document is stable
+func formatJSONValue(value reflect.Value) (string, error) {
+	document, err := json.Marshal(value.Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(document), nil
+}
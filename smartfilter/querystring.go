@@ -0,0 +1,327 @@
+package smartfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const QUERY_TAG_NAME = "query"
+const QUERY_OPERATOR_SEPARATOR = "__"
+const QUERY_LIST_SEPARATOR = ","
+const QUERY_ORDERING_PARAM = "ordering"
+
+// time formats accepted in query string values, tried in order
+var QUERY_TIME_FORMATS = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// QueryNamingFunc returns query parameter name for filter field
+type QueryNamingFunc func(filterField *FilterField) string
+
+// DefaultQueryNaming names query parameters as "field__op", e.g.
+// "created_at__ge". EQ operator uses plain field name.
+func DefaultQueryNaming(filterField *FilterField) string {
+	if filterField.Operator == OperatorEQ {
+		return filterField.Name
+	}
+	return fmt.Sprintf(
+		"%s%s%s", filterField.Name, QUERY_OPERATOR_SEPARATOR, strings.ToLower(string(filterField.Operator)),
+	)
+}
+
+// FieldError describes invalid value of a single filter field
+type FieldError struct {
+	Param   string
+	Field   string
+	Value   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Param, e.Message)
+}
+
+// ValidationErrors holds all field errors found while binding filter
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fieldError.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// QueryStringBinder populates filter structs from query parameters.
+// Parameter names are taken from "query" tag if present, otherwise from
+// Naming function. Ordering field is bound from OrderingParam.
+type QueryStringBinder struct {
+	Naming        QueryNamingFunc
+	ListSeparator string
+	OrderingParam string
+}
+
+func NewQueryStringBinder() *QueryStringBinder {
+	return &QueryStringBinder{
+		Naming:        DefaultQueryNaming,
+		ListSeparator: QUERY_LIST_SEPARATOR,
+		OrderingParam: QUERY_ORDERING_PARAM,
+	}
+}
+
+// FromQueryString populates filter struct, passed as pointer, from query
+// parameters using default naming scheme
+func FromQueryString(values url.Values, filter interface{}) error {
+	return NewQueryStringBinder().Bind(values, filter)
+}
+
+type taggedField struct {
+	field       reflect.StructField
+	filterField *FilterField
+}
+
+// getTaggedFields returns all filterfield tagged struct fields, regardless
+// of their values
+func getTaggedFields(st reflect.Type) ([]taggedField, error) {
+	res := make([]taggedField, 0)
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		tagValue := field.Tag.Get(TAG_NAME)
+		if len(tagValue) == 0 {
+			continue
+		}
+
+		filterField, err := newFilterField(tagValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %s", st.Name(), field.Name, err)
+		}
+		if field.Type.Kind() != reflect.Pointer {
+			return nil, fmt.Errorf("%s.%s: filter field must be a pointer", st.Name(), field.Name)
+		}
+		res = append(res, taggedField{field: field, filterField: filterField})
+	}
+	return res, nil
+}
+
+func (b *QueryStringBinder) paramName(tagged taggedField) string {
+	if name := tagged.field.Tag.Get(QUERY_TAG_NAME); len(name) > 0 {
+		return name
+	}
+	naming := b.Naming
+	if naming == nil {
+		naming = DefaultQueryNaming
+	}
+	return naming(tagged.filterField)
+}
+
+// orderingParam is ordering field of filter struct bound to a single
+// query parameter
+type orderingParam struct {
+	field     reflect.StructField
+	allowlist FieldAllowlist
+	param     string
+}
+
+// orderingParam returns ordering field of filter struct type, nil if filter
+// has no ordering field
+func (b *QueryStringBinder) orderingParam(st reflect.Type) (*orderingParam, error) {
+	field, allowlist, err := orderingField(st)
+	if err != nil || field == nil {
+		return nil, err
+	}
+	param := field.Tag.Get(QUERY_TAG_NAME)
+	if len(param) == 0 {
+		param = b.OrderingParam
+	}
+	if len(param) == 0 {
+		param = QUERY_ORDERING_PARAM
+	}
+	return &orderingParam{field: *field, allowlist: allowlist, param: param}, nil
+}
+
+// bind validates ordering against allowlist and sets it to filter value
+func (o *orderingParam) bind(v reflect.Value, raw string) *FieldError {
+	if _, err := ParseOrdering(raw, o.allowlist); err != nil {
+		return &FieldError{Param: o.param, Field: o.field.Name, Value: raw, Message: err.Error()}
+	}
+	v.FieldByIndex(o.field.Index).Set(reflect.ValueOf(&raw))
+	return nil
+}
+
+func (b *QueryStringBinder) listSeparator() string {
+	if len(b.ListSeparator) == 0 {
+		return QUERY_LIST_SEPARATOR
+	}
+	return b.ListSeparator
+}
+
+// Bind populates filter struct, passed as pointer, from query parameters.
+// Parameters not matching any filter field are ignored. Invalid values are
+// reported as ValidationErrors.
+func (b *QueryStringBinder) Bind(values url.Values, filter interface{}) error {
+	v := reflect.ValueOf(filter)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("filter must be a pointer to struct, got %T", filter)
+	}
+	v = v.Elem()
+
+	fields, err := getTaggedFields(v.Type())
+	if err != nil {
+		return err
+	}
+	ordering, err := b.orderingParam(v.Type())
+	if err != nil {
+		return err
+	}
+
+	validationErrors := ValidationErrors{}
+	for _, tagged := range fields {
+		param := b.paramName(tagged)
+		raw, ok := values[param]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+
+		value, err := b.parseParam(tagged.field.Type.Elem(), raw)
+		if err != nil {
+			validationErrors = append(validationErrors, FieldError{
+				Param:   param,
+				Field:   tagged.field.Name,
+				Value:   strings.Join(raw, b.listSeparator()),
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		ptr := reflect.New(tagged.field.Type.Elem())
+		ptr.Elem().Set(value)
+		v.FieldByIndex(tagged.field.Index).Set(ptr)
+	}
+
+	if ordering != nil {
+		if raw, ok := values[ordering.param]; ok && len(raw) > 0 {
+			if fieldError := ordering.bind(v, raw[0]); fieldError != nil {
+				validationErrors = append(validationErrors, *fieldError)
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+// parseParam parses query parameter values into value of type t. Slices
+// accept both separated lists and repeated parameters.
+func (b *QueryStringBinder) parseParam(t reflect.Type, raw []string) (reflect.Value, error) {
+	if t.Kind() != reflect.Slice {
+		return parseStringValue(t, raw[0])
+	}
+
+	items := make([]string, 0)
+	for _, r := range raw {
+		for _, item := range strings.Split(r, b.listSeparator()) {
+			item = strings.TrimSpace(item)
+			if len(item) > 0 {
+				items = append(items, item)
+			}
+		}
+	}
+
+	slice := reflect.MakeSlice(t, 0, len(items))
+	for _, item := range items {
+		value, err := parseStringValue(t.Elem(), item)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		slice = reflect.Append(slice, value)
+	}
+	return slice, nil
+}
+
+// parseStringValue parses textual representation of a filter value into
+// value of type t
+func parseStringValue(t reflect.Type, raw string) (reflect.Value, error) {
+	value := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return value, fmt.Errorf("invalid boolean value: %s", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("invalid integer value: %s", raw)
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("invalid unsigned integer value: %s", raw)
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("invalid float value: %s", raw)
+		}
+		value.SetFloat(f)
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Map:
+		return parseJSONValue(value, raw)
+	case reflect.Struct:
+		if t != reflect.TypeOf(time.Time{}) {
+			return parseJSONValue(value, raw)
+		}
+		tm, err := parseTime(raw)
+		if err != nil {
+			return value, err
+		}
+		value.Set(reflect.ValueOf(tm))
+	case reflect.Array:
+		if t != reflect.TypeOf(uuid.UUID{}) {
+			return value, fmt.Errorf("unsupported type: %v", t)
+		}
+		u, err := uuid.Parse(raw)
+		if err != nil {
+			return value, fmt.Errorf("invalid uuid value: %s", raw)
+		}
+		value.Set(reflect.ValueOf(u))
+	default:
+		return value, fmt.Errorf("unsupported type: %v", t)
+	}
+	return value, nil
+}
+
+// parseJSONValue parses json document into map or struct value, used by
+// json operators
+func parseJSONValue(value reflect.Value, raw string) (reflect.Value, error) {
+	if err := json.Unmarshal([]byte(raw), value.Addr().Interface()); err != nil {
+		return value, fmt.Errorf("invalid json value: %s", raw)
+	}
+	return value, nil
+}
+
+func parseTime(raw string) (time.Time, error) {
+	for _, format := range QUERY_TIME_FORMATS {
+		tm, err := time.Parse(format, raw)
+		if err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time value: %s", raw)
+}
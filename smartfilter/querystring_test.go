@@ -0,0 +1,282 @@
+package smartfilter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type queryStringFilter struct {
+	Status    *string      `filterfield:"field=status;operator=EQ"`
+	Statuses  *[]string    `filterfield:"field=status;operator=IN"`
+	Ids       *[]uuid.UUID `filterfield:"field=id;operator=IN"`
+	CntGT     *int         `filterfield:"field=cnt;operator=GT"`
+	Price     *float64     `filterfield:"field=price;operator=LE"`
+	Alive     *bool        `filterfield:"field=alive;operator=EQ"`
+	CreatedGE *time.Time   `filterfield:"field=created_at;operator=GE"`
+	Search    *string      `filterfield:"field=title,body;operator=SEARCH" query:"q"`
+	Ignored   *string
+}
+
+func TestFromQueryString(t *testing.T) {
+	t.Run("Populate filter", func(t *testing.T) {
+		id1 := uuid.New()
+		id2 := uuid.New()
+		values := url.Values{
+			"status":          {"active"},
+			"status__in":      {"active,pending", "closed"},
+			"id__in":          {id1.String() + "," + id2.String()},
+			"cnt__gt":         {"10"},
+			"price__le":       {"99.5"},
+			"alive":           {"true"},
+			"created_at__ge":  {"2024-01-01"},
+			"q":               {"quick fox"},
+			"unrelated_param": {"whatever"},
+		}
+
+		filter := queryStringFilter{}
+		err := FromQueryString(values, &filter)
+		assert.Nil(t, err)
+
+		assert.Equal(t, "active", *filter.Status)
+		assert.Equal(t, []string{"active", "pending", "closed"}, *filter.Statuses)
+		assert.Equal(t, []uuid.UUID{id1, id2}, *filter.Ids)
+		assert.Equal(t, 10, *filter.CntGT)
+		assert.Equal(t, 99.5, *filter.Price)
+		assert.True(t, *filter.Alive)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedGE)
+		assert.Equal(t, "quick fox", *filter.Search)
+		assert.Nil(t, filter.Ignored)
+	})
+
+	t.Run("Missing params leave fields nil", func(t *testing.T) {
+		filter := queryStringFilter{}
+		err := FromQueryString(url.Values{}, &filter)
+		assert.Nil(t, err)
+		assert.Equal(t, queryStringFilter{}, filter)
+	})
+
+	t.Run("Report validation errors", func(t *testing.T) {
+		values := url.Values{
+			"cnt__gt":        {"ten"},
+			"id__in":         {"not-uuid"},
+			"created_at__ge": {"yesterday"},
+		}
+
+		filter := queryStringFilter{}
+		err := FromQueryString(values, &filter)
+
+		validationErrors, ok := err.(ValidationErrors)
+		assert.True(t, ok)
+		assert.Equal(t, ValidationErrors{
+			{Param: "id__in", Field: "Ids", Value: "not-uuid", Message: "invalid uuid value: not-uuid"},
+			{Param: "cnt__gt", Field: "CntGT", Value: "ten", Message: "invalid integer value: ten"},
+			{Param: "created_at__ge", Field: "CreatedGE", Value: "yesterday", Message: "invalid time value: yesterday"},
+		}, validationErrors)
+		assert.Nil(t, filter.CntGT)
+	})
+
+	t.Run("Custom naming", func(t *testing.T) {
+		binder := NewQueryStringBinder()
+		binder.Naming = func(filterField *FilterField) string {
+			return filterField.Name + "[" + string(filterField.Operator) + "]"
+		}
+		binder.ListSeparator = "|"
+
+		filter := queryStringFilter{}
+		err := binder.Bind(url.Values{"status[IN]": {"a|b"}, "cnt[GT]": {"5"}}, &filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b"}, *filter.Statuses)
+		assert.Equal(t, 5, *filter.CntGT)
+	})
+
+	t.Run("Bind ordering", func(t *testing.T) {
+		filter := orderedFilter{}
+		err := FromQueryString(url.Values{"value": {"x"}, "ordering": {"-created,id"}}, &filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "x", *filter.Value)
+		assert.Equal(t, "-created,id", *filter.Order)
+
+		binder := NewQueryStringBinder()
+		binder.OrderingParam = "sort"
+		filter = orderedFilter{}
+		err = binder.Bind(url.Values{"sort": {"value"}}, &filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "value", *filter.Order)
+
+		filter = orderedFilter{}
+		err = FromQueryString(url.Values{"ordering": {"password"}}, &filter)
+		assert.Equal(t, ValidationErrors{
+			{Param: "ordering", Field: "Order", Value: "password", Message: "password: field not allowed: password"},
+		}, err)
+		assert.Nil(t, filter.Order)
+	})
+
+	t.Run("Fail on non pointer filter", func(t *testing.T) {
+		err := FromQueryString(url.Values{}, queryStringFilter{})
+		assert.EqualError(t, err, "filter must be a pointer to struct, got smartfilter.queryStringFilter")
+	})
+}
+
+func TestToURLValues(t *testing.T) {
+	id1 := uuid.MustParse("3f2b8f8e-56f1-4c4a-9d56-0a0b4f6f9c01")
+	id2 := uuid.MustParse("0b5f8c1e-2f6a-4d4e-8a3b-6c7d8e9f0a12")
+	status := "active"
+	statuses := []string{"pending", "active", "pending"}
+	ids := []uuid.UUID{id1, id2}
+	cnt := 10
+	price := 99.5
+	alive := false
+	created := time.Date(2024, 1, 1, 12, 30, 0, 500, time.FixedZone("CET", 3600))
+	search := "quick fox"
+
+	filter := queryStringFilter{
+		Status:    &status,
+		Statuses:  &statuses,
+		Ids:       &ids,
+		CntGT:     &cnt,
+		Price:     &price,
+		Alive:     &alive,
+		CreatedGE: &created,
+		Search:    &search,
+	}
+
+	t.Run("Serialize filter", func(t *testing.T) {
+		values, err := ToURLValues(filter)
+		assert.Nil(t, err)
+		assert.Equal(t, url.Values{
+			"status":         {"active"},
+			"status__in":     {"pending,active,pending"},
+			"id__in":         {id1.String() + "," + id2.String()},
+			"cnt__gt":        {"10"},
+			"price__le":      {"99.5"},
+			"alive":          {"false"},
+			"created_at__ge": {"2024-01-01T12:30:00.0000005+01:00"},
+			"q":              {"quick fox"},
+		}, values)
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		query, err := ToQueryString(&filter)
+		assert.Nil(t, err)
+
+		values, err := url.ParseQuery(query)
+		assert.Nil(t, err)
+
+		parsed := queryStringFilter{}
+		err = FromQueryString(values, &parsed)
+		assert.Nil(t, err)
+
+		assert.Equal(t, status, *parsed.Status)
+		assert.Equal(t, statuses, *parsed.Statuses)
+		assert.Equal(t, ids, *parsed.Ids)
+		assert.Equal(t, cnt, *parsed.CntGT)
+		assert.Equal(t, price, *parsed.Price)
+		assert.Equal(t, alive, *parsed.Alive)
+		assert.True(t, created.Equal(*parsed.CreatedGE))
+		assert.Equal(t, search, *parsed.Search)
+		assert.Nil(t, parsed.Ignored)
+	})
+
+	t.Run("Canonical string", func(t *testing.T) {
+		canonical, err := CanonicalString(filter)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			"alive=false&cnt__gt=10&created_at__ge=2024-01-01T11%3A30%3A00.0000005Z&id__in="+
+				id2.String()+"%2C"+id1.String()+
+				"&price__le=99.5&q=quick+fox&status=active&status__in=active%2Cpending",
+			canonical,
+		)
+
+		// same filter with reordered list and other time zone
+		otherStatuses := []string{"active", "pending"}
+		otherIds := []uuid.UUID{id2, id1}
+		otherCreated := created.UTC()
+		other := filter
+		other.Statuses = &otherStatuses
+		other.Ids = &otherIds
+		other.CreatedGE = &otherCreated
+
+		otherCanonical, err := CanonicalString(&other)
+		assert.Nil(t, err)
+		assert.Equal(t, canonical, otherCanonical)
+
+		empty, err := CanonicalString(queryStringFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, "", empty)
+	})
+
+	t.Run("Fail on separator in list value", func(t *testing.T) {
+		statuses := []string{"a,b"}
+		_, err := ToURLValues(queryStringFilter{Statuses: &statuses})
+		assert.EqualError(t, err, "status__in: list value contains separator: a,b")
+	})
+
+	t.Run("Round trip json values", func(t *testing.T) {
+		type attributes struct {
+			Color string `json:"color"`
+		}
+		type jsonFilter struct {
+			Attrs *map[string]interface{} `filterfield:"field=attrs;operator=JSON_CONTAINS"`
+			Meta  *attributes             `filterfield:"field=meta;operator=JSON_CONTAINS"`
+		}
+		attrs := map[string]interface{}{"size": "xl", "color": "red"}
+		meta := attributes{Color: "blue"}
+
+		values, err := ToURLValues(jsonFilter{Attrs: &attrs, Meta: &meta})
+		assert.Nil(t, err)
+		assert.Equal(t, url.Values{
+			"attrs__json_contains": {`{"color":"red","size":"xl"}`},
+			"meta__json_contains":  {`{"color":"blue"}`},
+		}, values)
+
+		parsed := jsonFilter{}
+		err = FromQueryString(values, &parsed)
+		assert.Nil(t, err)
+		assert.Equal(t, attrs, *parsed.Attrs)
+		assert.Equal(t, meta, *parsed.Meta)
+
+		err = FromQueryString(url.Values{"meta__json_contains": {"{"}}, &parsed)
+		assert.EqualError(t, err, "meta__json_contains: invalid json value: {")
+	})
+
+	t.Run("Serialize ordering", func(t *testing.T) {
+		value, ordering := "x", "-created,id"
+		values, err := ToURLValues(orderedFilter{Value: &value, Order: &ordering})
+		assert.Nil(t, err)
+		assert.Equal(t, url.Values{"value": {"x"}, "ordering": {"-created,id"}}, values)
+
+		parsed := orderedFilter{}
+		err = FromQueryString(values, &parsed)
+		assert.Nil(t, err)
+		assert.Equal(t, orderedFilter{Value: &value, Order: &ordering}, parsed)
+
+		// filters differing only in ordering aren't equal
+		otherOrdering := "id"
+		canonical, err := CanonicalString(orderedFilter{Value: &value, Order: &ordering})
+		assert.Nil(t, err)
+		otherCanonical, err := CanonicalString(orderedFilter{Value: &value, Order: &otherOrdering})
+		assert.Nil(t, err)
+		assert.NotEqual(t, canonical, otherCanonical)
+	})
+
+	t.Run("Fail on duplicate parameter names", func(t *testing.T) {
+		type duplicateFilter struct {
+			Status *string `filterfield:"field=status;operator=EQ"`
+			State  *string `filterfield:"field=state;operator=EQ" query:"status"`
+		}
+		_, err := ToURLValues(duplicateFilter{Status: &status})
+		assert.EqualError(t, err, "duplicate query parameter: status")
+
+		type duplicateOrderingFilter struct {
+			Ordering *string `filterfield:"field=ordering;operator=EQ"`
+			Order    *string `filterorder:"id"`
+		}
+		_, err = ToURLValues(duplicateOrderingFilter{})
+		assert.EqualError(t, err, "duplicate query parameter: ordering")
+	})
+}
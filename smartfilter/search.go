@@ -0,0 +1,133 @@
+package smartfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const DEFAULT_SEARCH_CONFIG = "simple"
+
+var searchConfigRegexp = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// searchColumns returns columns searched by SEARCH operator. Multiple
+// columns are declared as a list in field name, e.g. "field=title,body".
+func searchColumns(tableName string, filterField *FilterField) []string {
+	columns := make([]string, 0)
+	for _, name := range splitTrim(filterField.Name, TAG_LIST_SEPARATOR) {
+		columns = append(columns, fmt.Sprintf("%s.%s", tableName, name))
+	}
+	return columns
+}
+
+func searchConfig(filterField *FilterField) string {
+	if len(filterField.SearchConfig) == 0 {
+		return DEFAULT_SEARCH_CONFIG
+	}
+	return filterField.SearchConfig
+}
+
+// postgresSearchDocument concatenates searched columns into a single text
+// search document
+func postgresSearchDocument(tableName string, filterField *FilterField) string {
+	columns := searchColumns(tableName, filterField)
+	document := columns[0]
+	if len(columns) > 1 {
+		coalesced := make([]string, 0, len(columns))
+		for _, column := range columns {
+			coalesced = append(coalesced, fmt.Sprintf("coalesce(%s, '')", column))
+		}
+		document = strings.Join(coalesced, " || ' ' || ")
+	}
+	return fmt.Sprintf("to_tsvector('%s', %s)", searchConfig(filterField), document)
+}
+
+func postgresSearchQuery(filterField *FilterField) string {
+	return fmt.Sprintf("websearch_to_tsquery('%s', ?)", searchConfig(filterField))
+}
+
+func mysqlSearchMatch(tableName string, filterField *FilterField) string {
+	columns := searchColumns(tableName, filterField)
+	return fmt.Sprintf("MATCH (%s) AGAINST (? IN NATURAL LANGUAGE MODE)", strings.Join(columns, ", "))
+}
+
+// fts5SearchQuery converts search term into FTS5 query, limited to searched
+// columns. Every word is quoted, so FTS5 syntax in user input is not
+// interpreted.
+func fts5SearchQuery(filterField *FilterField, value string) string {
+	words := make([]string, 0)
+	for _, word := range strings.Fields(value) {
+		words = append(words, fmt.Sprintf(`"%s"`, strings.ReplaceAll(word, `"`, `""`)))
+	}
+	columns := splitTrim(filterField.Name, TAG_LIST_SEPARATOR)
+	return fmt.Sprintf("{%s} : %s", strings.Join(columns, " "), strings.Join(words, " "))
+}
+
+// searchCondition returns full text search condition for the dialect used
+// by query
+func searchCondition(query *gorm.DB, tableName string, filterField *FilterField, value string) clause.Expr {
+	switch dialectName(query) {
+	case dialectMySQL:
+		return clause.Expr{SQL: mysqlSearchMatch(tableName, filterField), Vars: []interface{}{value}}
+	case dialectSQLite:
+		return clause.Expr{
+			SQL:  fmt.Sprintf("%s MATCH ?", tableName),
+			Vars: []interface{}{fts5SearchQuery(filterField, value)},
+		}
+	}
+	return clause.Expr{
+		SQL: fmt.Sprintf(
+			"%s @@ %s", postgresSearchDocument(tableName, filterField), postgresSearchQuery(filterField),
+		),
+		Vars: []interface{}{value},
+	}
+}
+
+// searchRank returns ordering expression which sorts best search matches
+// first
+func searchRank(query *gorm.DB, tableName string, filterField *FilterField, value string) clause.Expr {
+	switch dialectName(query) {
+	case dialectMySQL:
+		return clause.Expr{
+			SQL:                fmt.Sprintf("%s DESC", mysqlSearchMatch(tableName, filterField)),
+			Vars:               []interface{}{value},
+			WithoutParentheses: true,
+		}
+	case dialectSQLite:
+		// FTS5 hidden rank column, lower values are better matches
+		return clause.Expr{SQL: "rank"}
+	}
+	return clause.Expr{
+		SQL: fmt.Sprintf(
+			"ts_rank(%s, %s) DESC", postgresSearchDocument(tableName, filterField), postgresSearchQuery(filterField),
+		),
+		Vars:               []interface{}{value},
+		WithoutParentheses: true,
+	}
+}
+
+// ApplySearchRank orders query by relevance of all SEARCH fields set in
+// filter. Ordering applied afterwards is used to break ties.
+func ApplySearchRank(model schema.Tabler, filter interface{}, query *gorm.DB) (*gorm.DB, error) {
+	tableName := model.TableName()
+
+	filterFields, err := parseFilterFields(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, filterField := range filterFields {
+		if filterField.Operator != OperatorSEARCH || filterField.strValue == nil {
+			continue
+		}
+		fieldTable := tableName
+		if len(filterField.Table) > 0 {
+			fieldTable = filterField.Table
+		}
+		query = AppendOrder(query, searchRank(query, fieldTable, filterField, *filterField.strValue))
+	}
+	return query, nil
+}
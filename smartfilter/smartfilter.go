@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
@@ -22,16 +24,33 @@ type QueryApplier interface {
 }
 
 var operatorHandlers = map[Operator]handlerFunc{
-	OperatorEQ:     handleOperatorEQ,
-	OperatorNE:     handleOperatorNE,
-	OperatorGT:     handleOperatorGT,
-	OperatorGE:     handleOperatorGE,
-	OperatorLT:     handleOperatorLT,
-	OperatorLE:     handleOperatorLE,
-	OperatorLIKE:   handleOperatorLIKE,
-	OperatorILIKE:  handleOperatorILIKE,
-	OperatorIN:     handleOperatorIN,
-	OperatorNOT_IN: handleOperatorNOT_IN,
+	OperatorEQ:      handleOperatorEQ,
+	OperatorNE:      handleOperatorNE,
+	OperatorGT:      handleOperatorGT,
+	OperatorGE:      handleOperatorGE,
+	OperatorLT:      handleOperatorLT,
+	OperatorLE:      handleOperatorLE,
+	OperatorLIKE:    handleOperatorLIKE,
+	OperatorILIKE:   handleOperatorILIKE,
+	OperatorIN:      handleOperatorIN,
+	OperatorNOT_IN:  handleOperatorNOT_IN,
+	OperatorBETWEEN: handleOperatorBETWEEN,
+
+	OperatorJSON_HAS_KEY:  handleOperatorJSON_HAS_KEY,
+	OperatorJSON_HAS_ANY:  handleOperatorJSON_HAS_ANY,
+	OperatorJSON_HAS_ALL:  handleOperatorJSON_HAS_ALL,
+	OperatorJSON_CONTAINS: handleOperatorJSON_CONTAINS,
+
+	OperatorCONTAINS:     handleOperatorCONTAINS,
+	OperatorCONTAINED_BY: handleOperatorCONTAINED_BY,
+	OperatorOVERLAPS:     handleOperatorOVERLAPS,
+	OperatorANY:          handleOperatorANY,
+
+	OperatorSEARCH: handleOperatorSEARCH,
+
+	OperatorIEQ: handleOperatorIEQ,
+	OperatorINE: handleOperatorINE,
+	OperatorIIN: handleOperatorIIN,
 }
 
 type ReflectedStructField struct {
@@ -43,8 +62,11 @@ type ReflectedStructField struct {
 func getFilterFields(filter interface{}) []ReflectedStructField {
 	res := make([]ReflectedStructField, 0)
 
-	st := reflect.TypeOf(filter)
-	reflectValue := reflect.ValueOf(filter)
+	reflectValue, err := indirectFilter(filter)
+	if err != nil || !reflectValue.IsValid() {
+		return res
+	}
+	st := reflectValue.Type()
 
 	for i := 0; i < st.NumField(); i++ {
 		field := st.Field(i)
@@ -72,6 +94,11 @@ func getFilterFields(filter interface{}) []ReflectedStructField {
 	return res
 }
 
+func isNilPointer(filter interface{}) bool {
+	reflectValue := reflect.ValueOf(filter)
+	return reflectValue.Kind() == reflect.Pointer && reflectValue.IsNil()
+}
+
 func getQueryApplierInterface(filter interface{}) QueryApplier {
 	queryApplier, ok := filter.(QueryApplier)
 	if ok {
@@ -80,13 +107,36 @@ func getQueryApplierInterface(filter interface{}) QueryApplier {
 	return nil
 }
 
-func ToQuery(model schema.Tabler, filter interface{}, query *gorm.DB) (*gorm.DB, error) {
-	st := reflect.TypeOf(filter)
+// indirectFilter returns filter struct value, filter may be passed as value
+// or pointer. Invalid value is returned for nil filter.
+func indirectFilter(filter interface{}) (reflect.Value, error) {
+	reflectValue := reflect.ValueOf(filter)
+	for reflectValue.Kind() == reflect.Pointer {
+		if reflectValue.IsNil() {
+			return reflect.Value{}, nil
+		}
+		reflectValue = reflectValue.Elem()
+	}
+	if reflectValue.IsValid() && reflectValue.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("filter must be a struct or pointer to struct, got %T", filter)
+	}
+	return reflectValue, nil
+}
 
-	tableName := model.TableName()
-	modelName := st.Name()
+// parseFilterFields creates filter fields from all non-nil tagged fields
+// of filter struct, with values set. Nil filter has no fields.
+func parseFilterFields(filter interface{}) ([]*FilterField, error) {
+	reflectValue, err := indirectFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if !reflectValue.IsValid() {
+		return []*FilterField{}, nil
+	}
+	modelName := reflectValue.Type().Name()
 
 	fields := getFilterFields(filter)
+	filterFields := make([]*FilterField, 0, len(fields))
 	for _, field := range fields {
 		filterField, err := newFilterField(field.tagValue)
 		if err != nil {
@@ -94,28 +144,89 @@ func ToQuery(model schema.Tabler, filter interface{}, query *gorm.DB) (*gorm.DB,
 		}
 
 		// must be called!
-		filterField.setValueFromReflection(field.value)
+		err = filterField.setValueFromReflection(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %s", modelName, field.name, err)
+		}
+		if err := filterField.checkValueShape(); err != nil {
+			return nil, fmt.Errorf("%s.%s: %s", modelName, field.name, err)
+		}
 
-		operatorHandler, ok := operatorHandlers[filterField.Operator]
-		if !ok {
-			return nil, fmt.Errorf("no handler for operator %s", filterField.Operator)
+		filterFields = append(filterFields, filterField)
+	}
+	return filterFields, nil
+}
+
+func applyFilterField(query *gorm.DB, tableName string, filterField *FilterField) (*gorm.DB, error) {
+	if len(filterField.Table) > 0 {
+		tableName = filterField.Table
+	}
+	operatorHandler, ok := operatorHandlers[filterField.Operator]
+	if !ok {
+		return nil, fmt.Errorf("no handler for operator %s", filterField.Operator)
+	}
+
+	query = operatorHandler(query, tableName, filterField)
+	if query == nil {
+		return nil, fmt.Errorf("invalid field type for operator %s", filterField.Operator)
+	}
+	return query, nil
+}
+
+func ToQuery(model schema.Tabler, filter interface{}, query *gorm.DB) (*gorm.DB, error) {
+	tableName := model.TableName()
+
+	// filters built at runtime carry their own conditions
+	if conditionFilter, ok := filter.(conditionFilter); ok {
+		condition, err := conditionFilter.filterCondition()
+		if err != nil {
+			return nil, err
 		}
+		return condition.apply(query, tableName)
+	}
 
-		query = operatorHandler(query, tableName, filterField)
-		if query == nil {
-			return nil, fmt.Errorf("invalid field type for operator %s", filterField.Operator)
+	filterFields, err := parseFilterFields(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, filterField := range filterFields {
+		query, err = applyFilterField(query, tableName, filterField)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	// apply custom filters, if interface exists
 	queryApplier := getQueryApplierInterface(filter)
-	if queryApplier != nil {
+	if queryApplier != nil && !isNilPointer(filter) {
 		query = queryApplier.ApplyQuery(query)
 	}
 
 	return query, nil
 }
 
+// IsEmpty reports whether filter has no conditions and matches all models.
+// Filters applying custom query are never considered empty.
+func IsEmpty(filter interface{}) (bool, error) {
+	if conditionFilter, ok := filter.(conditionFilter); ok {
+		condition, err := conditionFilter.filterCondition()
+		if err != nil {
+			return false, err
+		}
+		return condition.empty(), nil
+	}
+
+	if getQueryApplierInterface(filter) != nil && !isNilPointer(filter) {
+		return false, nil
+	}
+
+	filterFields, err := parseFilterFields(filter)
+	if err != nil {
+		return false, err
+	}
+	return len(filterFields) == 0, nil
+}
+
 func splitTrim(value string, separator string) []string {
 	var out []string = []string{}
 	for _, s := range strings.Split(value, separator) {
@@ -140,13 +251,40 @@ func newFilterField(tagValue string) (*FilterField, error) {
 
 		switch key {
 		case "field":
+			path, err := parseJSONPath(value)
+			if err != nil {
+				return nil, err
+			}
 			filterField.Name = value
+			filterField.jsonPath = path
 		case "operator":
 			operator := Operator(value)
 			if !slices.Contains(OPERATORS, operator) {
 				return nil, fmt.Errorf("unknown operator: %s", operator)
 			}
 			filterField.Operator = operator
+		case "timezone":
+			location, err := time.LoadLocation(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timezone: %s", value)
+			}
+			filterField.Location = location
+		case "config":
+			if !searchConfigRegexp.MatchString(value) {
+				return nil, fmt.Errorf("invalid search config: %s", value)
+			}
+			filterField.SearchConfig = value
+		case "table":
+			if !IsIdentifier(value) {
+				return nil, fmt.Errorf("invalid table: %s", value)
+			}
+			filterField.Table = value
+		case "citext":
+			citext, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid citext value: %s", value)
+			}
+			filterField.CIText = citext
 		default:
 			return nil, fmt.Errorf("invalid value key: %s", key)
 		}
@@ -158,6 +296,9 @@ func newFilterField(tagValue string) (*FilterField, error) {
 	if len(filterField.Operator) == 0 {
 		return nil, fmt.Errorf("missing operator in tag: %s", tagValue)
 	}
+	if strings.Contains(filterField.Name, TAG_LIST_SEPARATOR) && filterField.Operator != OperatorSEARCH {
+		return nil, fmt.Errorf("multiple fields are supported only by %s operator", OperatorSEARCH)
+	}
 
 	return &filterField, nil
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
@@ -198,6 +199,37 @@ func TestFilterField(t *testing.T) {
 		assert.EqualError(t, err, "missing field name in tag: operator=EQ")
 	})
 
+	t.Run("Parse timezone", func(t *testing.T) {
+		filterField, err := newFilterField("field=created_at; operator=GT; timezone=Europe/Zagreb")
+		assert.Nil(t, err)
+		assert.Equal(t, "Europe/Zagreb", filterField.Location.String())
+	})
+
+	t.Run("Fail on invalid timezone", func(t *testing.T) {
+		filterField, err := newFilterField("field=created_at; operator=GT; timezone=Mars/Olympus")
+		assert.Nil(t, filterField)
+		assert.EqualError(t, err, "invalid timezone: Mars/Olympus")
+	})
+
+	t.Run("Parse search config", func(t *testing.T) {
+		filterField, err := newFilterField("field=title,body; operator=SEARCH; config=english")
+		assert.Nil(t, err)
+		assert.Equal(t, "title,body", filterField.Name)
+		assert.Equal(t, "english", filterField.SearchConfig)
+	})
+
+	t.Run("Fail on multiple fields for non search operator", func(t *testing.T) {
+		filterField, err := newFilterField("field=title,body; operator=EQ")
+		assert.Nil(t, filterField)
+		assert.EqualError(t, err, "multiple fields are supported only by SEARCH operator")
+	})
+
+	t.Run("Parse citext", func(t *testing.T) {
+		filterField, err := newFilterField("field=email; operator=IEQ; citext=true")
+		assert.Nil(t, err)
+		assert.True(t, filterField.CIText)
+	})
+
 	t.Run("Fail on missing operator", func(t *testing.T) {
 		filterField, err := newFilterField("field=field_1")
 		assert.Nil(t, filterField)
@@ -227,3 +259,234 @@ func TestSmartfilterApplyQuery(t *testing.T) {
 		assert.NotNil(t, queryApplier)
 	})
 }
+
+func TestToQueryNativeValues(t *testing.T) {
+	type TestFilter struct {
+		Id        *uuid.UUID   `filterfield:"field=id;operator=EQ"`
+		Ids       *[]uuid.UUID `filterfield:"field=id;operator=IN"`
+		Cnts      *[]int       `filterfield:"field=cnt;operator=IN"`
+		CreatedGT *time.Time   `filterfield:"field=created_at;operator=GT;timezone=UTC"`
+		Between   *[]time.Time `filterfield:"field=created_at;operator=BETWEEN"`
+	}
+
+	db, _ := NewMockDB()
+	zagreb, _ := time.LoadLocation("Europe/Zagreb")
+
+	t.Run("Bind time and uuid values natively", func(t *testing.T) {
+		id := uuid.New()
+		ids := []uuid.UUID{uuid.New(), uuid.New()}
+		cnts := []int{1, 2, 3}
+		created := time.Date(2024, 5, 26, 18, 8, 0, 123456789, zagreb)
+		between := []time.Time{created, created.Add(time.Hour)}
+		filter := TestFilter{
+			Id:        &id,
+			Ids:       &ids,
+			Cnts:      &cnts,
+			CreatedGT: &created,
+			Between:   &between,
+		}
+
+		stmt := db.Session(&gorm.Session{DryRun: true}).Model(&MyModel{})
+		query, err := ToQuery(MyModel{}, filter, stmt)
+		assert.Nil(t, err)
+		result := query.Find(&[]MyModel{})
+
+		assert.Equal(
+			t,
+			"SELECT * FROM my_models WHERE my_models.id = $1 AND my_models.id IN ($2,$3) AND my_models.cnt IN ($4,$5,$6) AND my_models.created_at > $7 AND (my_models.created_at BETWEEN $8 AND $9)",
+			result.Statement.SQL.String(),
+		)
+		vars := result.Statement.Vars
+		assert.Equal(t, id, vars[0])
+		assert.Equal(t, ids[0], vars[1])
+		assert.Equal(t, ids[1], vars[2])
+		assert.Equal(t, int64(3), vars[5])
+
+		// time is converted to requested location, keeping nanosecond precision
+		boundTime, ok := vars[6].(time.Time)
+		assert.True(t, ok)
+		assert.Equal(t, time.UTC, boundTime.Location())
+		assert.Equal(t, 123456789, boundTime.Nanosecond())
+		assert.True(t, created.Equal(boundTime))
+		assert.Equal(t, between[1], vars[8])
+	})
+
+	t.Run("Bind empty slices", func(t *testing.T) {
+		type EmptyFilter struct {
+			Cnts    *[]int    `filterfield:"field=cnt;operator=IN"`
+			NotIds  *[]string `filterfield:"field=id;operator=NOT_IN"`
+			Between *[]int    `filterfield:"field=cnt;operator=BETWEEN"`
+		}
+
+		stmt := db.Session(&gorm.Session{DryRun: true}).Model(&MyModel{})
+		query, err := ToQuery(MyModel{}, EmptyFilter{Cnts: &[]int{}, NotIds: &[]string{}}, stmt)
+		assert.Nil(t, err)
+		result := query.Find(&[]MyModel{})
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.cnt IN ($1) AND my_models.id NOT IN ($2)", result.Statement.SQL.String())
+
+		_, err = ToQuery(MyModel{}, EmptyFilter{Between: &[]int{}}, db)
+		assert.EqualError(t, err, "invalid field type for operator BETWEEN")
+	})
+
+	t.Run("Fail on scalar slice operator fields", func(t *testing.T) {
+		type ScalarFilter struct {
+			Between *int   `filterfield:"field=cnt;operator=BETWEEN"`
+			In      *int   `filterfield:"field=cnt;operator=IN"`
+			Eq      *[]int `filterfield:"field=cnt;operator=EQ"`
+		}
+		cnt := 5
+		_, err := ToQuery(MyModel{}, ScalarFilter{Between: &cnt}, db)
+		assert.EqualError(t, err, "ScalarFilter.Between: operator BETWEEN requires a list value")
+
+		_, err = ToQuery(MyModel{}, ScalarFilter{In: &cnt}, db)
+		assert.EqualError(t, err, "ScalarFilter.In: operator IN requires a list value")
+
+		_, err = ToQuery(MyModel{}, ScalarFilter{Eq: &[]int{cnt}}, db)
+		assert.EqualError(t, err, "ScalarFilter.Eq: operator EQ requires a single value")
+	})
+
+	t.Run("Fail on unsupported slice element", func(t *testing.T) {
+		type InvalidFilter struct {
+			Values *[]map[string]int `filterfield:"field=values;operator=IN"`
+		}
+		values := []map[string]int{}
+		_, err := ToQuery(MyModel{}, InvalidFilter{Values: &values}, db)
+		assert.EqualError(t, err, "InvalidFilter.Values: unsupported slice element type: map[string]int")
+	})
+}
+
+type pointerQueryApplierFilter struct {
+	Cnt *int `filterfield:"field=cnt;operator=GT"`
+}
+
+func (f *pointerQueryApplierFilter) ApplyQuery(query *gorm.DB) *gorm.DB {
+	return query.Where("my_models.value IS NOT NULL")
+}
+
+func TestToQueryFilterPointers(t *testing.T) {
+	db, _ := NewMockDB()
+	toSQL := func(filter interface{}) (string, error) {
+		var err error
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			var query *gorm.DB
+			query, err = ToQuery(MyModel{}, filter, tx.Model(&MyModel{}))
+			if err != nil {
+				return tx
+			}
+			return query.Find(&[]MyModel{})
+		})
+		return sql, err
+	}
+
+	t.Run("Pointer to filter", func(t *testing.T) {
+		cnt := 5
+		sql, err := toSQL(&pointerQueryApplierFilter{Cnt: &cnt})
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.cnt > 5 AND my_models.value IS NOT NULL", sql)
+	})
+
+	t.Run("Nil filters", func(t *testing.T) {
+		var filter *pointerQueryApplierFilter
+		sql, err := toSQL(filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models", sql)
+
+		sql, err = toSQL(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models", sql)
+	})
+
+	t.Run("Fail on non-struct filter", func(t *testing.T) {
+		_, err := toSQL(map[string]int{"cnt": 5})
+		assert.EqualError(t, err, "filter must be a struct or pointer to struct, got map[string]int")
+	})
+}
+
+func TestIsEmpty(t *testing.T) {
+	cnt := 5
+	for _, testCase := range []struct {
+		name     string
+		filter   interface{}
+		expected bool
+	}{
+		{"Nil filter", nil, true},
+		{"Nil pointer", (*pointerQueryApplierFilter)(nil), true},
+		{"Nil fields", orderedFilter{}, true},
+		{"Field set", orderedFilter{Value: new(string)}, false},
+		{"Custom query", &pointerQueryApplierFilter{}, false},
+		{"Empty builder", New(), true},
+		{"Empty group", New().WhereGroup(New()), true},
+		{"Builder condition", New().Where("cnt", OperatorGT, cnt), false},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			empty, err := IsEmpty(testCase.filter)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.expected, empty)
+		})
+	}
+
+	_, err := IsEmpty(map[string]int{"cnt": cnt})
+	assert.EqualError(t, err, "filter must be a struct or pointer to struct, got map[string]int")
+}
+
+func TestToQueryJoinedTable(t *testing.T) {
+	db, _ := NewMockDB()
+
+	t.Run("Qualify column with table option", func(t *testing.T) {
+		type TestFilter struct {
+			Value    *string `filterfield:"field=value;operator=EQ"`
+			Customer *string `filterfield:"field=name;operator=EQ;table=c"`
+			Color    *string `filterfield:"field=attrs->>color;operator=EQ;table=c"`
+		}
+		value, customer, color := "x", "ACME", "red"
+
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			query, _ := ToQuery(MyModel{}, TestFilter{Value: &value, Customer: &customer, Color: &color}, tx.Model(&MyModel{}))
+			return query.Find(&[]MyModel{})
+		})
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.value = 'x' AND c.name = 'ACME' AND c.attrs->>'color' = 'red'", sql)
+
+		_, err := Matches(MyModel{}, TestFilter{Customer: &customer})
+		assert.EqualError(t, err, "joined table fields can't be evaluated in memory: c.name")
+	})
+
+	t.Run("Fail on invalid table", func(t *testing.T) {
+		type TestFilter struct {
+			Value *string `filterfield:"field=value;operator=EQ;table=c.x"`
+		}
+		value := "x"
+		_, err := ToQuery(MyModel{}, TestFilter{Value: &value}, db)
+		assert.EqualError(t, err, "TestFilter.Value: invalid table: c.x")
+	})
+}
+
+func TestApplySearchRank(t *testing.T) {
+	type TestFilter struct {
+		Id     *int    `filterfield:"field=id;operator=EQ"`
+		Search *string `filterfield:"field=title,body;operator=SEARCH"`
+	}
+
+	t.Run("Order by rank of search fields only", func(t *testing.T) {
+		db, _ := NewMockDBWithDialect("mysql")
+		id := 1
+		search := "fox"
+		filter := TestFilter{Id: &id, Search: &search}
+
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			query, err := ApplySearchRank(MyModel{}, filter, tx.Model(&MyModel{}))
+			assert.Nil(t, err)
+			return query.Find(&[]MyModel{})
+		})
+		assert.Equal(t, "SELECT * FROM my_models ORDER BY MATCH (my_models.title, my_models.body) AGAINST ('fox' IN NATURAL LANGUAGE MODE) DESC", sql)
+	})
+
+	t.Run("No search fields set", func(t *testing.T) {
+		db, _ := NewMockDB()
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			query, err := ApplySearchRank(MyModel{}, TestFilter{}, tx.Model(&MyModel{}))
+			assert.Nil(t, err)
+			return query.Find(&[]MyModel{})
+		})
+		assert.Equal(t, "SELECT * FROM my_models", sql)
+	})
+}
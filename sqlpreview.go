@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// Statement is SQL generated by repository operation, with bind variables
+type Statement struct {
+	SQL  string
+	Vars []interface{}
+	// explained holds SQL with variables inlined, for display only
+	explained string
+}
+
+// String returns SQL with variables inlined, it must not be executed
+func (s Statement) String() string {
+	return s.explained
+}
+
+// statementRecorder is a logger which records executed statements instead
+// of logging them. It is used with dry run sessions.
+type statementRecorder struct {
+	logger.Interface
+	statements []Statement
+	sql        string
+	vars       []interface{}
+}
+
+func newStatementRecorder() *statementRecorder {
+	return &statementRecorder{Interface: logger.Discard}
+}
+
+func (r *statementRecorder) LogMode(level logger.LogLevel) logger.Interface {
+	return r
+}
+
+// ParamsFilter receives statement before variables are inlined
+func (r *statementRecorder) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	r.sql, r.vars = sql, params
+	return sql, params
+}
+
+func (r *statementRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	explained, _ := fc()
+	r.statements = append(r.statements, Statement{SQL: r.sql, Vars: r.vars, explained: explained})
+}
+
+// SQLPreview generates SQL of repository operations without executing them
+type SQLPreview[T schema.Tabler] struct {
+	repo  *RepoBase[T]
+	hooks bool
+}
+
+// ToSQL returns preview of repository operations. Preview methods return
+// the generated statement instead of executing it. Save and update hooks
+// are skipped, unless enabled by WithHooks.
+//
+//	statement, err := repo.ToSQL().List(filter, &options)
+func (m *RepoBase[T]) ToSQL() *SQLPreview[T] {
+	return &SQLPreview[T]{repo: m}
+}
+
+// WithHooks returns preview which calls save and update hooks, e.g. when
+// hooks change saved values. Hooks must not have side effects, the
+// statement is never executed.
+func (p *SQLPreview[T]) WithHooks() *SQLPreview[T] {
+	return &SQLPreview[T]{repo: p.repo, hooks: true}
+}
+
+// capture runs operation on dry run copy of repository and returns its
+// first statement
+func (p *SQLPreview[T]) capture(operation func(repo *RepoBase[T]) error) (*Statement, error) {
+	recorder := newStatementRecorder()
+	repo := p.repo.withConnection(p.repo.dbConn.Session(&gorm.Session{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	}))
+	// dry run affects no rows, there is nothing to limit in a transaction
+	repo.MaxRowsAffected = 0
+	if !p.hooks {
+		repo.PreSave, repo.PostSave = nil, nil
+		repo.PreUpdate, repo.PostUpdate = nil, nil
+	}
+
+	err := operation(repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(recorder.statements) == 0 {
+		return nil, fmt.Errorf("no statement generated")
+	}
+	return &recorder.statements[0], nil
+}
+
+func (p *SQLPreview[T]) List(filter interface{}, options *ListOptions) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.List(filter, options)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) Get(filter interface{}, options *GetOptions) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.Get(filter, options)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) Exists(filter interface{}) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.Exists(filter)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) Count(filter interface{}) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.Count(filter)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) Save(model *T) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.Save(model)
+		return err
+	})
+}
+
+// SaveReturning previews save returning stored values. MySQL selects saved
+// model in a transaction afterwards, so save itself is previewed.
+func (p *SQLPreview[T]) SaveReturning(model *T, options *ReturningOptions) (*Statement, error) {
+	if !supportsReturning(p.repo.dbConn) {
+		return p.Save(model)
+	}
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.SaveReturning(model, options)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) Update(filter interface{}, values map[string]any) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.Update(filter, values)
+		return err
+	})
+}
+
+// UpdateReturning previews update returning affected rows. MySQL selects
+// and locks filtered rows in a transaction and updates them by their ids,
+// dry run selects no ids, so update of the filtered rows is previewed.
+func (p *SQLPreview[T]) UpdateReturning(filter interface{}, values map[string]any, options *ReturningOptions) (*Statement, error) {
+	if !supportsReturning(p.repo.dbConn) {
+		return p.Update(filter, values)
+	}
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.UpdateReturning(filter, values, options)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) UpdatePatch(filter interface{}, patch interface{}) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.UpdatePatch(filter, patch)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) UpdateFields(filter interface{}, model *T, fields []string) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.UpdateFields(filter, model, fields)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) UpdateAll(values map[string]any) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.UpdateAll(values)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) Delete(filter interface{}) (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.Delete(filter)
+		return err
+	})
+}
+
+// DeleteReturning previews delete returning affected rows, on MySQL delete
+// of the filtered rows is previewed, see UpdateReturning
+func (p *SQLPreview[T]) DeleteReturning(filter interface{}, options *ReturningOptions) (*Statement, error) {
+	if !supportsReturning(p.repo.dbConn) {
+		return p.Delete(filter)
+	}
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.DeleteReturning(filter, options)
+		return err
+	})
+}
+
+func (p *SQLPreview[T]) DeleteAll() (*Statement, error) {
+	return p.capture(func(repo *RepoBase[T]) error {
+		_, err := repo.DeleteAll()
+		return err
+	})
+}
+
+// Explanation is a query plan returned by EXPLAIN, rows are returned as
+// database formats them
+type Explanation struct {
+	Statement Statement
+	Columns   []string
+	Rows      [][]string
+}
+
+// String returns query plan rows, one per line, with columns separated
+// by tabs
+func (e Explanation) String() string {
+	lines := make([]string, 0, len(e.Rows))
+	for _, row := range e.Rows {
+		lines = append(lines, strings.Join(row, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SQLExplainer runs EXPLAIN for statements of repository operations
+type SQLExplainer[T schema.Tabler] struct {
+	preview *SQLPreview[T]
+	analyze bool
+}
+
+// Explain returns explainer of repository operations. With analyze set,
+// statements are executed by EXPLAIN ANALYZE inside a transaction, which
+// is always rolled back. SQLite does not support analyze.
+//
+//	explanation, err := repo.Explain(false).List(filter, &options)
+func (m *RepoBase[T]) Explain(analyze bool) *SQLExplainer[T] {
+	return &SQLExplainer[T]{preview: m.ToSQL(), analyze: analyze}
+}
+
+var errExplainRollback = errors.New("explain rollback")
+
+func (e *SQLExplainer[T]) explainPrefix() (string, error) {
+	dialect := e.preview.repo.dbConn.Dialector.Name()
+	switch {
+	case dialect == "sqlite" && e.analyze:
+		return "", fmt.Errorf("explain analyze is not supported by sqlite")
+	case dialect == "sqlite":
+		return "EXPLAIN QUERY PLAN", nil
+	case e.analyze:
+		return "EXPLAIN ANALYZE", nil
+	}
+	return "EXPLAIN", nil
+}
+
+func (e *SQLExplainer[T]) explain(statement *Statement, err error) (*Explanation, error) {
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := e.explainPrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := Explanation{Statement: *statement}
+	run := func(tx *gorm.DB) error {
+		// statement is passed to the driver as generated, gorm would try
+		// to bind placeholders again
+		rows, err := tx.Statement.ConnPool.QueryContext(
+			tx.Statement.Context, fmt.Sprintf("%s %s", prefix, statement.SQL), statement.Vars...,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		explanation.Columns, err = rows.Columns()
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			values := make([]interface{}, len(explanation.Columns))
+			pointers := make([]interface{}, len(values))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				return err
+			}
+
+			row := make([]string, 0, len(values))
+			for _, value := range values {
+				if b, ok := value.([]byte); ok {
+					value = string(b)
+				}
+				if value == nil {
+					value = "NULL"
+				}
+				row = append(row, fmt.Sprint(value))
+			}
+			explanation.Rows = append(explanation.Rows, row)
+		}
+		return rows.Err()
+	}
+
+	dbConn := e.preview.repo.dbConn
+	if !e.analyze {
+		err = run(dbConn)
+	} else {
+		// analyzed statements are executed, changes must not be kept
+		err = dbConn.Transaction(func(tx *gorm.DB) error {
+			if err := run(tx); err != nil {
+				return err
+			}
+			return errExplainRollback
+		})
+		if errors.Is(err, errExplainRollback) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+func (e *SQLExplainer[T]) List(filter interface{}, options *ListOptions) (*Explanation, error) {
+	return e.explain(e.preview.List(filter, options))
+}
+
+func (e *SQLExplainer[T]) Get(filter interface{}, options *GetOptions) (*Explanation, error) {
+	return e.explain(e.preview.Get(filter, options))
+}
+
+func (e *SQLExplainer[T]) Exists(filter interface{}) (*Explanation, error) {
+	return e.explain(e.preview.Exists(filter))
+}
+
+func (e *SQLExplainer[T]) Count(filter interface{}) (*Explanation, error) {
+	return e.explain(e.preview.Count(filter))
+}
+
+func (e *SQLExplainer[T]) Save(model *T) (*Explanation, error) {
+	return e.explain(e.preview.Save(model))
+}
+
+func (e *SQLExplainer[T]) SaveReturning(model *T, options *ReturningOptions) (*Explanation, error) {
+	return e.explain(e.preview.SaveReturning(model, options))
+}
+
+func (e *SQLExplainer[T]) Update(filter interface{}, values map[string]any) (*Explanation, error) {
+	return e.explain(e.preview.Update(filter, values))
+}
+
+func (e *SQLExplainer[T]) UpdateReturning(filter interface{}, values map[string]any, options *ReturningOptions) (*Explanation, error) {
+	return e.explain(e.preview.UpdateReturning(filter, values, options))
+}
+
+func (e *SQLExplainer[T]) UpdatePatch(filter interface{}, patch interface{}) (*Explanation, error) {
+	return e.explain(e.preview.UpdatePatch(filter, patch))
+}
+
+func (e *SQLExplainer[T]) UpdateFields(filter interface{}, model *T, fields []string) (*Explanation, error) {
+	return e.explain(e.preview.UpdateFields(filter, model, fields))
+}
+
+func (e *SQLExplainer[T]) UpdateAll(values map[string]any) (*Explanation, error) {
+	return e.explain(e.preview.UpdateAll(values))
+}
+
+func (e *SQLExplainer[T]) Delete(filter interface{}) (*Explanation, error) {
+	return e.explain(e.preview.Delete(filter))
+}
+
+func (e *SQLExplainer[T]) DeleteReturning(filter interface{}, options *ReturningOptions) (*Explanation, error) {
+	return e.explain(e.preview.DeleteReturning(filter, options))
+}
+
+func (e *SQLExplainer[T]) DeleteAll() (*Explanation, error) {
+	return e.explain(e.preview.DeleteAll())
+}
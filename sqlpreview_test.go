@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLPreview(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		cnt := 5
+		value := "some value"
+		filter := MyModelFilter{
+			Value: &value,
+			CntGT: &cnt,
+		}
+		options := ListOptions{
+			Ordering:   []Order{{Field: "cnt", Direction: OrderDESC}},
+			Pagination: &Pagination{Limit: 10},
+		}
+
+		statement, err := repo.ToSQL().List(filter, &options)
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT * FROM my_models WHERE my_models.value = $1 AND my_models.cnt > $2 ORDER BY cnt DESC LIMIT $3`, statement.SQL)
+		assert.Equal(t, []interface{}{value, int64(cnt), 10}, statement.Vars)
+		assert.Equal(t, `SELECT * FROM my_models WHERE my_models.value = 'some value' AND my_models.cnt > 5 ORDER BY cnt DESC LIMIT 10`, statement.String())
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Statements are not executed", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		id := uuid.New()
+		filter := MyModelFilter{
+			Id: &id,
+		}
+
+		statement, err := repo.ToSQL().Update(filter, map[string]any{"cnt": 1})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE my_models SET cnt=$1 WHERE my_models.id = $2", statement.SQL)
+		assert.Equal(t, []interface{}{1, id}, statement.Vars)
+
+		statement, err = repo.ToSQL().Delete(filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "DELETE FROM my_models WHERE my_models.id = $1", statement.SQL)
+
+		statement, err = repo.ToSQL().Count(filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT count(*) FROM my_models WHERE my_models.id = $1", statement.SQL)
+
+		statement, err = repo.ToSQL().Exists(filter)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT 1 FROM my_models WHERE my_models.id = $1 LIMIT $2", statement.SQL)
+
+		statement, err = repo.ToSQL().Get(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.id = $1 ORDER BY my_models.id LIMIT $2", statement.SQL)
+
+		hooked := false
+		repo.PreSave = func(model *MyModel) error {
+			hooked = true
+			return nil
+		}
+		statement, err = repo.ToSQL().Save(&MyModel{Id: &id, Value: "x"})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE my_models SET value=$1,cnt=$2 WHERE id = $3", statement.SQL)
+		assert.False(t, hooked)
+
+		_, err = repo.ToSQL().WithHooks().Save(&MyModel{Id: &id, Value: "x"})
+		assert.Nil(t, err)
+		assert.True(t, hooked)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Returning previews on mysql", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+		db.Dialector = dialectOverride{Dialector: db.Dialector, name: "mysql"}
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		id := uuid.New()
+		filter := MyModelFilter{Id: &id}
+
+		statement, err := repo.ToSQL().UpdateReturning(filter, map[string]any{"cnt": 1}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE `my_models` SET `cnt`=$1 WHERE my_models.id = $2", statement.SQL)
+
+		statement, err = repo.ToSQL().DeleteReturning(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "DELETE FROM `my_models` WHERE my_models.id = $1", statement.SQL)
+
+		statement, err = repo.ToSQL().SaveReturning(&MyModel{Id: &id, Value: "x"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE `my_models` SET `value`=$1,`cnt`=$2 WHERE `id` = $3", statement.SQL)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Filter errors", func(t *testing.T) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		_, err := repo.ToSQL().List(map[string]int{}, nil)
+		assert.EqualError(t, err, "filter must be a struct or pointer to struct, got map[string]int")
+	})
+}
+
+func TestExplain(t *testing.T) {
+	t.Run("Explain", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		cnt := 5
+		filter := MyModelFilter{
+			CntGT: &cnt,
+		}
+
+		sql := "EXPLAIN SELECT * FROM my_models WHERE my_models.cnt > $1"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(cnt).
+			WillReturnRows(
+				sqlmock.NewRows([]string{"QUERY PLAN"}).
+					AddRow("Seq Scan on my_models  (cost=0.00..1.04 rows=1 width=68)").
+					AddRow("  Filter: (cnt > 5)"),
+			)
+
+		explanation, err := repo.Explain(false).List(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT * FROM my_models WHERE my_models.cnt > $1", explanation.Statement.SQL)
+		assert.Equal(t, []string{"QUERY PLAN"}, explanation.Columns)
+		assert.Equal(t, "Seq Scan on my_models  (cost=0.00..1.04 rows=1 width=68)\n  Filter: (cnt > 5)", explanation.String())
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Explain analyze rolls back", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[MyModel]{}
+		repo.Init(db, nil)
+
+		cnt := 5
+		filter := MyModelFilter{
+			CntGT: &cnt,
+		}
+
+		sql := "EXPLAIN ANALYZE DELETE FROM my_models WHERE my_models.cnt > $1"
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(cnt).
+			WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow("Delete on my_models"))
+		mock.ExpectRollback()
+
+		explanation, err := repo.Explain(true).Delete(filter)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"Delete on my_models"}}, explanation.Rows)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"gorm.io/gorm/schema"
+)
+
+// TypedRepository is Repository with filter parameters of type F, so
+// passing wrong filter type is caught at compile time
+type TypedRepository[T schema.Tabler, F any] interface {
+	List(filter F, options *ListOptions) (*[]T, error)
+	Get(filter F, options *GetOptions) (*T, error)
+	Exists(filter F) (bool, error)
+	Count(filter F) (int64, error)
+	Save(model *T) (*T, error)
+	SaveReturning(model *T, options *ReturningOptions) (*T, error)
+	Update(filter F, values map[string]any) (int64, error)
+	UpdateReturning(filter F, values map[string]any, options *ReturningOptions) (*[]T, error)
+	UpdatePatch(filter F, patch interface{}) (int64, error)
+	UpdateFields(filter F, model *T, fields []string) (int64, error)
+	UpdateAll(values map[string]any) (int64, error)
+	Delete(filter F) (int64, error)
+	DeleteReturning(filter F, options *ReturningOptions) (*[]T, error)
+	DeleteAll() (int64, error)
+}
+
+var _ TypedRepository[schema.Tabler, any] = (*TypedRepoBase[schema.Tabler, any])(nil)
+
+// TypedRepoBase is RepoBase parameterised by its filter type F. Filter
+// can be a struct or a pointer to struct, nil pointer filters nothing.
+//
+//	repo := repository.TypedRepoBase[MyModel, *MyModelFilter]{}
+//	repo.Init(db, nil)
+type TypedRepoBase[T schema.Tabler, F any] struct {
+	RepoBase[T]
+}
+
+func (m *TypedRepoBase[T, F]) List(filter F, options *ListOptions) (*[]T, error) {
+	return m.RepoBase.List(filter, options)
+}
+
+func (m *TypedRepoBase[T, F]) Get(filter F, options *GetOptions) (*T, error) {
+	return m.RepoBase.Get(filter, options)
+}
+
+func (m *TypedRepoBase[T, F]) Exists(filter F) (bool, error) {
+	return m.RepoBase.Exists(filter)
+}
+
+func (m *TypedRepoBase[T, F]) Count(filter F) (int64, error) {
+	return m.RepoBase.Count(filter)
+}
+
+func (m *TypedRepoBase[T, F]) Update(filter F, values map[string]any) (int64, error) {
+	return m.RepoBase.Update(filter, values)
+}
+
+func (m *TypedRepoBase[T, F]) UpdateReturning(filter F, values map[string]any, options *ReturningOptions) (*[]T, error) {
+	return m.RepoBase.UpdateReturning(filter, values, options)
+}
+
+func (m *TypedRepoBase[T, F]) UpdatePatch(filter F, patch interface{}) (int64, error) {
+	return m.RepoBase.UpdatePatch(filter, patch)
+}
+
+func (m *TypedRepoBase[T, F]) UpdateFields(filter F, model *T, fields []string) (int64, error) {
+	return m.RepoBase.UpdateFields(filter, model, fields)
+}
+
+func (m *TypedRepoBase[T, F]) Delete(filter F) (int64, error) {
+	return m.RepoBase.Delete(filter)
+}
+
+func (m *TypedRepoBase[T, F]) DeleteReturning(filter F, options *ReturningOptions) (*[]T, error) {
+	return m.RepoBase.DeleteReturning(filter, options)
+}
+
+// Untyped returns repository accepting any filter type, e.g. to be wrapped
+// with decorators
+func (m *TypedRepoBase[T, F]) Untyped() Repository[T] {
+	return &m.RepoBase
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedRepoBase(t *testing.T) {
+	t.Run("Pointer filter", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := TypedRepoBase[MyModel, *MyModelFilter]{}
+		repo.Init(db, nil)
+
+		id := uuid.New()
+		filter := MyModelFilter{
+			Id: &id,
+		}
+
+		sql := "SELECT * FROM my_models WHERE my_models.id = $1"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(id)
+
+		_, err := repo.List(&filter, nil)
+		assert.Nil(t, err)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Nil pointer filter", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := TypedRepoBase[MyModel, *MyModelFilter]{}
+		repo.Init(db, nil)
+
+		sql := "SELECT count(*) FROM my_models"
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql)))
+
+		_, err := repo.Count(nil)
+		assert.Nil(t, err)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Value filter", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := TypedRepoBase[MyModel, MyModelFilter]{}
+		repo.Init(db, nil)
+
+		cnt := 10
+		filter := MyModelFilter{
+			CntGT: &cnt,
+		}
+
+		sql := "DELETE FROM my_models WHERE my_models.cnt > $1"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs(cnt).
+			WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+
+		deleted, err := repo.Delete(filter)
+		assert.Equal(t, int64(2), deleted)
+		assert.Nil(t, err)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
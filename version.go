@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	REPOSITORY_TAG_NAME = "repository"
+	VERSION_TAG_VALUE   = "version"
+)
+
+// ErrStaleObject is returned when versioned model was changed or deleted
+// since it was read, i.e. no row with expected version exists
+var ErrStaleObject = errors.New("stale object")
+
+// lookUpVersionField returns version field given by column or, if column
+// is empty, tagged with `repository:"version"`. Nil is returned if model
+// isn't versioned. Version must be a number or a time.
+func lookUpVersionField(modelSchema *schema.Schema, column string) (*schema.Field, error) {
+	var field *schema.Field
+	if len(column) > 0 {
+		field = modelSchema.LookUpField(column)
+		if field == nil || len(field.DBName) == 0 {
+			return nil, fmt.Errorf("unknown version column: %s", column)
+		}
+	} else {
+		for _, f := range modelSchema.Fields {
+			if f.StructField.Tag.Get(REPOSITORY_TAG_NAME) == VERSION_TAG_VALUE {
+				field = f
+				break
+			}
+		}
+		if field == nil {
+			return nil, nil
+		}
+	}
+
+	switch field.DataType {
+	case schema.Int, schema.Uint, schema.Float, schema.Time:
+		return field, nil
+	}
+	return nil, fmt.Errorf("version column must be a number or a time: %s", field.DBName)
+}
+
+// nextVersion returns version following the current one, as update
+// expression or value. Time versions are set to the current time of
+// application, never of database, so save and update bump them the same.
+func nextVersion(field *schema.Field) interface{} {
+	if field.DataType == schema.Time {
+		return currentTime(field)
+	}
+	return Increment(1)
+}
+
+// bumpVersion sets version of model to the next one and returns previous
+// version, which is zero for new models
+func bumpVersion(field *schema.Field, model reflect.Value) (previous interface{}, isZero bool, err error) {
+	ctx := context.Background()
+	previous, isZero = field.ValueOf(ctx, model)
+	current := previous
+	if isZero {
+		// NULL version starts from zero
+		current = reflect.Zero(indirectType(field.FieldType)).Interface()
+	}
+	next := nextVersion(field)
+	if expression, ok := next.(UpdateExpression); ok {
+		next, err = expression.evaluate(field, current)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if field.FieldType.Kind() == reflect.Pointer {
+		// new pointer is set, setting the value would overwrite previous
+		// version shared with the caller
+		elemType := field.FieldType.Elem()
+		nextValue := reflect.ValueOf(next)
+		if nextValue.CanConvert(elemType) {
+			ptr := reflect.New(elemType)
+			ptr.Elem().Set(nextValue.Convert(elemType))
+			next = ptr.Interface()
+		}
+	}
+	return previous, isZero, field.Set(ctx, model, next)
+}
+
+// versionValues adds next version to update values. Version given in
+// values is removed and returned as expected version, version given by
+// update expression is left as it is.
+func versionValues(field *schema.Field, values map[string]any) (map[string]any, interface{}, bool) {
+	var (
+		versioned = make(map[string]any, len(values)+1)
+		expected  interface{}
+		found     bool
+		explicit  bool
+	)
+	for column, value := range values {
+		if column != field.DBName && column != field.Name {
+			versioned[column] = value
+			continue
+		}
+		if _, ok := value.(UpdateExpression); ok {
+			versioned[column] = value
+			explicit = true
+			continue
+		}
+		expected, found = value, true
+	}
+	if !explicit {
+		versioned[field.DBName] = nextVersion(field)
+	}
+	return versioned, expected, found
+}
+
+// versionCondition checks version column of table
+func versionCondition(table string, field *schema.Field, version interface{}) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: table, Name: field.DBName}, Value: version}
+}
+
+// versionField returns version field of repository model, nil if model
+// isn't versioned
+func (m *RepoBase[T]) versionField() (*schema.Field, error) {
+	var model T
+	modelSchema, err := parseSchema(m.dbConn, &model)
+	if err != nil {
+		return nil, err
+	}
+	return lookUpVersionField(modelSchema, m.VersionField)
+}
+
+// versioned adds next version to update values and returns condition
+// checking expected version, if it is given by values
+func (m *RepoBase[T]) versioned(values map[string]any) (map[string]any, clause.Expression, error) {
+	field, err := m.versionField()
+	if err != nil || field == nil {
+		return values, nil, err
+	}
+	values, expected, found := versionValues(field, values)
+	if !found {
+		return values, nil, nil
+	}
+	var model T
+	return values, versionCondition(model.TableName(), field, expected), nil
+}
+
+// saveVersioned saves model, checking and bumping its version. Models with
+// zero version are saved as usual, other ones are updated only if stored
+// version is the same. Version is left intact on failure and in dry run.
+func (m *RepoBase[T]) saveVersioned(query *gorm.DB, model *T) error {
+	field, err := m.versionField()
+	if err != nil {
+		return err
+	}
+	if field == nil {
+		return query.Save(model).Error
+	}
+
+	modelValue := reflect.ValueOf(model).Elem()
+	previous, isZero, err := bumpVersion(field, modelValue)
+	if err != nil {
+		return err
+	}
+	restore := func(err error) error {
+		_ = field.Set(context.Background(), modelValue, previous)
+		return err
+	}
+	if query.DryRun {
+		// previewed statement holds the next version, model doesn't
+		defer restore(nil)
+	}
+
+	if isZero {
+		if err := query.Save(model).Error; err != nil {
+			return restore(err)
+		}
+		return nil
+	}
+
+	result := query.Model(model).
+		Select("*").
+		Where(versionCondition((*model).TableName(), field, previous)).
+		Updates(model)
+	if result.Error != nil {
+		return restore(result.Error)
+	}
+	if result.RowsAffected == 0 && !result.DryRun {
+		return restore(ErrStaleObject)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type Document struct {
+	Id      uint
+	Title   string
+	Version int `repository:"version"`
+}
+
+func (m Document) TableName() string {
+	return "documents"
+}
+
+type TimedDocument struct {
+	Id      uint
+	Title   string
+	Version *time.Time `repository:"version" gorm:"precision:3"`
+}
+
+func (m TimedDocument) TableName() string {
+	return "timed_documents"
+}
+
+// capturedArg matches any argument and records it
+type capturedArg struct {
+	value driver.Value
+}
+
+func (a *capturedArg) Match(value driver.Value) bool {
+	a.value = value
+	return true
+}
+
+type DocumentFilter struct {
+	Id *uint `filterfield:"field=id;operator=EQ"`
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	id := uint(1)
+	filter := DocumentFilter{Id: &id}
+
+	t.Run("Save checks and bumps version", func(t *testing.T) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[Document]{}
+		repo.Init(db, nil)
+
+		model := Document{Title: "new"}
+		statement, err := repo.ToSQL().Save(&model)
+		assert.Nil(t, err)
+		assert.Equal(t, "INSERT INTO documents (title,version) VALUES ($1,$2) RETURNING id", statement.SQL)
+		assert.Equal(t, []interface{}{"new", 1}, statement.Vars)
+		assert.Equal(t, 0, model.Version)
+
+		model = Document{Id: id, Title: "title", Version: 3}
+		statement, err = repo.ToSQL().Save(&model)
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE documents SET title=$1,version=$2 WHERE documents.version = $3 AND id = $4", statement.SQL)
+		assert.Equal(t, []interface{}{"title", 4, 3, id}, statement.Vars)
+		// preview leaves model intact
+		assert.Equal(t, 3, model.Version)
+	})
+
+	t.Run("Save of stale model", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[Document]{}
+		repo.Init(db, nil)
+
+		sql := "UPDATE documents SET title=$1,version=$2 WHERE documents.version = $3 AND id = $4"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs("title", 4, 3, id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		model := Document{Id: id, Title: "title", Version: 3}
+		_, err := repo.Save(&model)
+		assert.ErrorIs(t, err, ErrStaleObject)
+		assert.Equal(t, 3, model.Version)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Update checks expected version", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[Document]{}
+		repo.Init(db, nil)
+
+		statement, err := repo.ToSQL().Update(filter, map[string]any{"title": "title"})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE documents SET title=$1,version=version + $2 WHERE documents.id = $3", statement.SQL)
+
+		sql := "UPDATE documents SET title=$1,version=version + $2 WHERE documents.id = $3 AND documents.version = $4"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs("title", 1, uint64(id), 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		_, err = repo.UpdateFields(filter, &Document{Title: "title", Version: 3}, []string{"title", "Version"})
+		assert.ErrorIs(t, err, ErrStaleObject)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Version column given by options", func(t *testing.T) {
+		sqldb, db, _ := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[Counter]{}
+		repo.Init(db, &RepoOptions{VersionField: "seen_at"})
+		counterId := uint(1)
+
+		statement, err := repo.ToSQL().Update(CounterFilter{Id: &counterId}, map[string]any{"hits": 1})
+		assert.Nil(t, err)
+		assert.Equal(t, "UPDATE counters SET hits=$1,seen_at=$2 WHERE counters.id = $3", statement.SQL)
+		assert.IsType(t, time.Time{}, statement.Vars[1])
+
+		repo.Init(db, &RepoOptions{VersionField: "label"})
+		_, err = repo.ToSQL().Update(CounterFilter{Id: &counterId}, map[string]any{"hits": 1})
+		assert.EqualError(t, err, "version column must be a number or a time: label")
+	})
+
+	t.Run("Time version is truncated to column precision", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[TimedDocument]{}
+		repo.Init(db, nil)
+
+		previous := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		sql := "UPDATE timed_documents SET title=$1,version=$2 WHERE timed_documents.version = $3 AND id = $4"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs("title", sqlmock.AnyArg(), previous, id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		model := TimedDocument{Id: id, Title: "title", Version: &previous}
+		_, err := repo.Save(&model)
+		assert.Nil(t, err)
+		assert.True(t, model.Version.After(previous))
+		assert.Equal(t, model.Version.Truncate(time.Millisecond), *model.Version)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+
+		// microseconds are assumed without precision tag
+		counters := MemoryRepo[Counter]{}
+		counters.Init(&RepoOptions{VersionField: "seen_at"})
+		counter := Counter{Id: id}
+		_, err = counters.Save(&counter)
+		assert.Nil(t, err)
+		if assert.NotNil(t, counter.SeenAt) {
+			assert.Equal(t, counter.SeenAt.Truncate(time.Microsecond), *counter.SeenAt)
+		}
+	})
+
+	t.Run("Save and update bump time version the same way", func(t *testing.T) {
+		sqldb, db, mock := NewMockDB()
+		defer sqldb.Close()
+
+		repo := RepoBase[TimedDocument]{}
+		repo.Init(db, nil)
+
+		saved := &capturedArg{}
+		sql := "INSERT INTO timed_documents (title,version) VALUES ($1,$2) RETURNING id"
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs("new", saved).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+		mock.ExpectCommit()
+
+		model := TimedDocument{Title: "new"}
+		_, err := repo.Save(&model)
+		assert.Nil(t, err)
+		if !assert.NotNil(t, model.Version) {
+			return
+		}
+		assert.Equal(t, *model.Version, saved.value)
+
+		// version read back is the one written, both come from the same clock
+		updated := &capturedArg{}
+		sql = "UPDATE timed_documents SET title=$1,version=$2 WHERE timed_documents.id = $3 AND timed_documents.version = $4"
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf("^%s$", regexp.QuoteMeta(sql))).
+			WithArgs("updated", updated, uint64(id), *model.Version).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		affected, err := repo.Update(DocumentFilter{Id: &id}, map[string]any{"title": "updated", "version": *model.Version})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), affected)
+		if version, ok := updated.value.(time.Time); assert.True(t, ok) {
+			assert.Equal(t, version.Truncate(time.Millisecond), version)
+			assert.False(t, version.Before(*model.Version))
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Memory repository", func(t *testing.T) {
+		repo := MemoryRepo[Document]{}
+		repo.Init(nil)
+
+		model := Document{Title: "new"}
+		_, err := repo.Save(&model)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, model.Version)
+
+		stale := model
+		model.Title = "updated"
+		_, err = repo.Save(&model)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, model.Version)
+
+		_, err = repo.Save(&stale)
+		assert.ErrorIs(t, err, ErrStaleObject)
+		assert.Equal(t, 1, stale.Version)
+
+		filter := DocumentFilter{Id: &model.Id}
+		_, err = repo.Update(filter, map[string]any{"title": "title", "version": 1})
+		assert.ErrorIs(t, err, ErrStaleObject)
+
+		updated, err := repo.Update(filter, map[string]any{"title": "title", "version": 2})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		stored, err := repo.Get(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, Document{Id: model.Id, Title: "title", Version: 3}, *stored)
+	})
+}